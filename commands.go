@@ -1,25 +1,47 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
 )
 
-func commandExit(cfg *config, args ...string) error {
+func commandExit(ctx context.Context, cfg *config, args ...string) error {
 	fmt.Println("Closing the Pokedex... Goodbye!")
+	if err := cfg.saveProfile(cfg.autosave); err != nil {
+		fmt.Println("Saving profile failed:", err)
+	}
+	cfg.pokeapiClient.Close()
 	os.Exit(0)
 	return nil
 }
 
-func commandHelp(cfg *config, args ...string) error {
-	fmt.Println("Welcome to the Pokedex!\nUsage:\n\nhelp: Displays a help message\nexit: Exit the Pokedex")
+func commandHelp(ctx context.Context, cfg *config, args ...string) error {
+	fmt.Print("Welcome to the Pokedex!\nUsage:\n\n")
+
+	commands := getCommands()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", commands[name].name, commands[name].description)
+	}
 	return nil
 }
 
-func commandMap(cfg *config, args ...string) error {
-	locations, err := cfg.pokeapiClient.GetLocationAreas(cfg.Next)
+func commandMap(ctx context.Context, cfg *config, args ...string) error {
+	locations, err := cfg.pokeapiClient.GetLocationAreasContext(ctx, cfg.Next)
 	if err != nil {
 		return err
 	}
@@ -33,13 +55,12 @@ func commandMap(cfg *config, args ...string) error {
 	return nil
 }
 
-func commandMapb(cfg *config, args ...string) error {
+func commandMapb(ctx context.Context, cfg *config, args ...string) error {
 	if cfg.Previous == nil {
-		fmt.Println("you're on the first page")
 		return errors.New("you're on the first page")
 	}
 
-	locations, err := cfg.pokeapiClient.GetLocationAreas(cfg.Previous)
+	locations, err := cfg.pokeapiClient.GetLocationAreasContext(ctx, cfg.Previous)
 	if err != nil {
 		return err
 	}
@@ -53,16 +74,17 @@ func commandMapb(cfg *config, args ...string) error {
 	return nil
 }
 
-func commandExplore(cfg *config, args ...string) error {
+func commandExplore(ctx context.Context, cfg *config, args ...string) error {
 	if len(args) == 0 {
 		return errors.New("please provide a location name to explore")
 	}
 	locationName := args[0]
-	location, err := cfg.pokeapiClient.GetLocationArea(locationName)
+	location, err := cfg.pokeapiClient.GetLocationAreaContext(ctx, locationName)
 	if err != nil {
 		return err
 	}
 
+	cfg.location = location.Name
 	fmt.Printf("Exploring %s...\n", locationName)
 	fmt.Println("Found Pokemon:")
 	for _, pokemon := range location.PokemonEncounters {
@@ -71,24 +93,38 @@ func commandExplore(cfg *config, args ...string) error {
 	return nil
 }
 
-func commandCatch(cfg *config, args ...string) error {
+func commandCatch(ctx context.Context, cfg *config, args ...string) error {
 	if len(args) == 0 {
 		return errors.New("please provide a pokemon name to catch")
 	}
 	pokemonName := args[0]
 	fmt.Printf("Throwing a Pokeball at %s...\n", pokemonName)
 
-	pokemon, err := cfg.pokeapiClient.CatchPokemon(pokemonName)
+	pokemon, err := cfg.pokeapiClient.CatchPokemonContext(ctx, pokemonName)
 	if err != nil {
 		return err
 	}
 
-	time.Sleep(1 * time.Second) // Add a 1-second delay
+	// Wait for the pokeball to land, cut short if the command is interrupted
+	select {
+	case <-time.After(cfg.catchDelay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
 	// Simulate catching the Pokemon
-	if pokemon.AttemptCatch() {
-		cfg.pokedex[pokemon.Name] = pokemon
-		fmt.Printf("%s was caught!\n", pokemon.Name)
+	cfg.attempts[pokemon.Name]++
+	if cfg.rollCatch(pokemon) {
+		caught := pokedex.FromAPI(pokemon)
+		caught.Level = pokedex.RandomLevel()
+		caught.RollIVs()
+		caught.CaughtAt = time.Now()
+		caught.Location = cfg.location
+		caught.Attempts = cfg.attempts[pokemon.Name]
+		caught = cfg.pokedex.Add(caught)
+		delete(cfg.attempts, pokemon.Name)
+		fmt.Printf("%s was caught! (#%d)\n", pokemon.Name, caught.ID)
+		autosave(cfg)
 	} else {
 		fmt.Printf("%s escaped!\n", pokemon.Name)
 	}
@@ -96,46 +132,394 @@ func commandCatch(cfg *config, args ...string) error {
 	return nil
 }
 
-func commandInspect(cfg *config, args ...string) error {
+// rollCatch reports whether a pokeball thrown at p catches it.
+func (cfg *config) rollCatch(p pokeapi.Pokemon) bool {
+	if cfg.catchRoll != nil {
+		return cfg.catchRoll(p)
+	}
+	return p.AttemptCatch()
+}
+
+func commandInspect(ctx context.Context, cfg *config, args ...string) error {
 	if len(args) == 0 {
-		fmt.Println("please provide a pokemon name to catch")
 		return errors.New("you must provide a pokemon name")
 	}
-	pokemonName := args[0]
-
-	pokemon, ok := cfg.pokedex[pokemonName]
-	if !ok {
-		fmt.Println("you have not caught that pokemon")
-		return errors.New("you have not caught that pokemon")
+	caught, err := cfg.pokedex.Lookup(args[0])
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Name: %s\n", pokemon.Name)
-	fmt.Printf("Height: %d\n", pokemon.Height)
-	fmt.Printf("Weight: %d\n", pokemon.Weight)
+	fmt.Printf("ID: %d\n", caught.ID)
+	fmt.Printf("Name: %s\n", caught.Name)
+	if caught.Nickname != "" {
+		fmt.Printf("Nickname: %s\n", caught.Nickname)
+	}
+	if caught.Level > 0 {
+		fmt.Printf("Level: %d\n", caught.Level)
+	}
+	fmt.Printf("Height: %d\n", caught.Height)
+	fmt.Printf("Weight: %d\n", caught.Weight)
 	fmt.Println("Stats:")
-	for _, item := range pokemon.Stats {
-		fmt.Printf("  -%s: %d\n", item.Stat.Name, item.BaseStat)
+	for _, stat := range caught.Stats {
+		if caught.Level > 0 {
+			fmt.Printf("  -%s: %d (base %d, iv %d)\n", stat.Name, stat.At(caught.Level), stat.Value, stat.IV)
+		} else {
+			// Caught before levels were recorded; only the base stat is known.
+			fmt.Printf("  -%s: %d\n", stat.Name, stat.Value)
+		}
 	}
 	fmt.Println("Types:")
-	for _, item := range pokemon.Types {
-		fmt.Printf("  -%s\n", item.Type.Name)
+	for _, name := range caught.Types {
+		fmt.Printf("  -%s\n", name)
+	}
+	fmt.Println("Abilities:")
+	for _, name := range caught.Abilities {
+		fmt.Printf("  -%s\n", name)
+	}
+	if !caught.CaughtAt.IsZero() {
+		fmt.Printf("Caught: %s", caught.CaughtAt.Format(time.DateTime))
+		if caught.Location != "" {
+			fmt.Printf(" at %s", caught.Location)
+		}
+		fmt.Printf(" after %d attempts\n", caught.Attempts)
+	}
+	if caught.Note != "" {
+		fmt.Printf("Note: %s\n", caught.Note)
+	}
+
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, caught.Species)
+	if err != nil {
+		// The caught pokemon is still worth showing without its species.
+		fmt.Println("Species details unavailable:", err)
+		return nil
+	}
+	if genus := localized(species.Genus, cfg.language); genus != "" {
+		fmt.Printf("Species: %s\n", genus)
+	}
+	if entry := localized(species.FlavorText, cfg.language); entry != "" {
+		fmt.Printf("Pokedex entry: %s\n", entry)
 	}
 
 	return nil
 }
 
-func commandPokedex(cfg *config, args ...string) error {
+// localized looks text up in language, falling back to English.
+func localized(text func(language string) string, language string) string {
+	if s := text(language); s != "" {
+		return s
+	}
+	return text(defaultLanguage)
+}
+
+func commandPokedex(ctx context.Context, cfg *config, args ...string) error {
 	// check length of pokedex
-	if len(cfg.pokedex) == 0 {
+	if cfg.pokedex.Len() == 0 {
 		fmt.Println("Your Pokedex is empty. Go catch some Pokemon!")
 		return nil
 	}
 
-	// Print all caught Pokemon
-	fmt.Println("Your Pokedex:")
-	for name := range cfg.pokedex {
-		fmt.Println("  -", name)
+	// Group the caught individuals by species, keeping them in id order
+	bySpecies := map[string][]pokedex.Pokemon{}
+	for _, caught := range cfg.pokedex.All() {
+		bySpecies[caught.Name] = append(bySpecies[caught.Name], caught)
+	}
+	names := make([]string, 0, len(bySpecies))
+	for name := range bySpecies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("Your Pokedex (%d pokemon of %d species):\n", cfg.pokedex.Len(), len(names))
+	for _, name := range names {
+		fmt.Printf("  - %s x%d\n", name, len(bySpecies[name]))
+		for _, caught := range bySpecies[name] {
+			line := fmt.Sprintf("    #%d", caught.ID)
+			if caught.Nickname != "" {
+				line += " " + caught.Nickname
+			}
+			if caught.Level > 0 {
+				line += fmt.Sprintf(" level %d", caught.Level)
+			}
+			fmt.Println(line)
+			if caught.Note != "" {
+				fmt.Printf("      note: %s\n", caught.Note)
+			}
+		}
+	}
+
+	return nil
+}
+
+func commandRelease(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide the pokemon to release")
+	}
+
+	caught, err := cfg.pokedex.Lookup(args[0])
+	if err != nil {
+		return err
+	}
+	cfg.pokedex.Remove(caught.ID)
+	fmt.Printf("#%d %s was released\n", caught.ID, caught.DisplayName())
+	autosave(cfg)
+	return nil
+}
+
+func commandNickname(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide the pokemon to nickname")
+	}
+
+	caught, err := cfg.pokedex.Lookup(args[0])
+	if err != nil {
+		return err
+	}
+	// Pokemon are looked up by a single word, so a nickname must be one too
+	// and must not be taken by another pokemon.
+	if len(args) > 2 {
+		return errors.New("nicknames must be a single word")
+	}
+	var nickname string
+	if len(args) == 2 {
+		nickname = args[1]
+	}
+	if _, err := strconv.Atoi(nickname); err == nil {
+		return errors.New("nicknames cannot be numbers, they would be mistaken for ids")
+	}
+	for _, other := range cfg.pokedex.All() {
+		if nickname != "" && other.ID != caught.ID && strings.EqualFold(other.Nickname, nickname) {
+			return fmt.Errorf("#%d %s is already called %s", other.ID, other.Name, other.Nickname)
+		}
+	}
+
+	caught.Nickname = nickname
+	cfg.pokedex.Update(caught)
+	if nickname == "" {
+		fmt.Printf("Cleared the nickname of #%d %s\n", caught.ID, caught.Name)
+	} else {
+		fmt.Printf("#%d %s is now called %s\n", caught.ID, caught.Name, nickname)
+	}
+	autosave(cfg)
+	return nil
+}
+
+func commandNote(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide the pokemon to add a note to")
+	}
+
+	caught, err := cfg.pokedex.Lookup(args[0])
+	if err != nil {
+		return err
 	}
 
+	caught.Note = strings.Join(args[1:], " ")
+	cfg.pokedex.Update(caught)
+	if caught.Note == "" {
+		fmt.Printf("Cleared the note on #%d %s\n", caught.ID, caught.DisplayName())
+	} else {
+		fmt.Printf("Noted on #%d %s\n", caught.ID, caught.DisplayName())
+	}
+	autosave(cfg)
 	return nil
 }
+
+func commandCache(ctx context.Context, cfg *config, args ...string) error {
+	cache := cfg.pokeapiClient.Cache()
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "clear":
+			if err := cache.Clear(); err != nil {
+				return err
+			}
+			fmt.Println("Cache cleared")
+			return nil
+		case "evict":
+			if len(args) < 2 {
+				return errors.New("please provide a url to evict")
+			}
+			if !cache.Remove(args[1]) {
+				return fmt.Errorf("%s is not cached", args[1])
+			}
+			fmt.Println("Evicted", args[1])
+			return nil
+		default:
+			return fmt.Errorf("unknown cache subcommand: %s", args[0])
+		}
+	}
+
+	stats := cache.Stats()
+	fmt.Printf("Hits: %d\n", stats.Hits)
+	fmt.Printf("Misses: %d\n", stats.Misses)
+	fmt.Printf("Evictions: %d\n", stats.Evictions)
+	fmt.Printf("Entries: %d\n", stats.Entries)
+	fmt.Printf("Bytes: %d\n", stats.Bytes)
+
+	entries := cache.Entries()
+	if len(entries) > 0 {
+		fmt.Println("Keys:")
+	}
+	for _, entry := range entries {
+		fmt.Printf("  - %s (%s old)\n", entry.Key, entry.Age.Round(time.Second))
+	}
+	return nil
+}
+
+func commandOffline(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "on":
+			cfg.pokeapiClient.SetOffline(true)
+		case "off":
+			cfg.pokeapiClient.SetOffline(false)
+		default:
+			return fmt.Errorf("unknown offline mode: %s (use on or off)", args[0])
+		}
+	}
+
+	if cfg.pokeapiClient.Offline() {
+		fmt.Println("Offline mode is on")
+	} else {
+		fmt.Println("Offline mode is off")
+	}
+	return nil
+}
+
+func commandEvolutions(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide a pokemon name")
+	}
+
+	pokemon, err := cfg.pokeapiClient.CatchPokemonContext(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	species, err := cfg.pokeapiClient.GetPokemonSpeciesContext(ctx, pokemon.Species.Name)
+	if err != nil {
+		return err
+	}
+	chainID, err := pokeapi.ResourceID(species.EvolutionChain.URL)
+	if err != nil {
+		return err
+	}
+	chain, err := cfg.pokeapiClient.GetEvolutionChainContext(ctx, chainID)
+	if err != nil {
+		return err
+	}
+
+	printChainLink(chain.Chain, 0)
+	return nil
+}
+
+// printChainLink prints link and its evolutions as an indented tree.
+func printChainLink(link pokeapi.ChainLink, depth int) {
+	line := strings.Repeat("  ", depth)
+	if depth > 0 {
+		line += "-> "
+	}
+	line += link.Species.Name
+
+	conditions := make([]string, 0, len(link.EvolutionDetails))
+	for _, detail := range link.EvolutionDetails {
+		conditions = append(conditions, detail.String())
+	}
+	if len(conditions) > 0 {
+		line += " (" + strings.Join(conditions, " or ") + ")"
+	}
+	fmt.Println(line)
+
+	for _, next := range link.EvolvesTo {
+		printChainLink(next, depth+1)
+	}
+}
+
+func commandMove(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide a move name")
+	}
+
+	move, err := cfg.pokeapiClient.GetMoveContext(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name: %s\n", move.Name)
+	fmt.Printf("Type: %s\n", move.Type.Name)
+	fmt.Printf("Damage class: %s\n", move.DamageClass.Name)
+	fmt.Printf("Power: %s\n", optionalInt(move.Power))
+	fmt.Printf("Accuracy: %s\n", optionalInt(move.Accuracy))
+	fmt.Printf("PP: %s\n", optionalInt(move.PP))
+	if effect := localized(move.Effect, cfg.language); effect != "" {
+		fmt.Printf("Effect: %s\n", effect)
+	}
+	return nil
+}
+
+func commandAbility(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide an ability name")
+	}
+
+	ability, err := cfg.pokeapiClient.GetAbilityContext(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name: %s\n", ability.Name)
+	if effect := localized(ability.Effect, cfg.language); effect != "" {
+		fmt.Printf("Effect: %s\n", effect)
+	}
+	fmt.Println("Pokemon:")
+	for _, item := range ability.Pokemon {
+		if item.IsHidden {
+			fmt.Printf("  -%s (hidden)\n", item.Pokemon.Name)
+		} else {
+			fmt.Printf("  -%s\n", item.Pokemon.Name)
+		}
+	}
+	return nil
+}
+
+func commandType(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide a type name")
+	}
+
+	pokemonType, err := cfg.pokeapiClient.GetTypeContext(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	relations := pokemonType.DamageRelations
+	fmt.Printf("Name: %s\n", pokemonType.Name)
+	fmt.Println("Damage relations:")
+	fmt.Printf("  -double damage to: %s\n", typeNames(relations.DoubleDamageTo))
+	fmt.Printf("  -half damage to: %s\n", typeNames(relations.HalfDamageTo))
+	fmt.Printf("  -no damage to: %s\n", typeNames(relations.NoDamageTo))
+	fmt.Printf("  -double damage from: %s\n", typeNames(relations.DoubleDamageFrom))
+	fmt.Printf("  -half damage from: %s\n", typeNames(relations.HalfDamageFrom))
+	fmt.Printf("  -no damage from: %s\n", typeNames(relations.NoDamageFrom))
+	return nil
+}
+
+// optionalInt formats a value PokeAPI may leave null, such as a status
+// move's power.
+func optionalInt(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.Itoa(*n)
+}
+
+// typeNames joins type names for display, or returns "none".
+func typeNames(types []pokeapi.NamedAPIResource) string {
+	if len(types) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,650 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/httpreplay"
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokeapitest"
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
+)
+
+var record = flag.Bool("record", false, "re-record testdata cassettes against $POKEAPI_BASE_URL, or the fake PokeAPI if unset")
+
+func newTestConfig(t *testing.T) *config {
+	t.Helper()
+	server := pokeapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	client := pokeapi.NewClient(time.Second, time.Minute, pokeapi.WithBaseURL(server.BaseURL()))
+	t.Cleanup(client.Close)
+
+	return &config{
+		pokedex:       pokedex.New(),
+		pokeapiClient: client,
+		language:      defaultLanguage,
+		attempts:      map[string]int{},
+		catchRoll:     catchRolls(),
+	}
+}
+
+// catchRolls returns a catch roll that succeeds or fails as listed, then
+// always succeeds.
+func catchRolls(results ...bool) func(pokeapi.Pokemon) bool {
+	return func(pokeapi.Pokemon) bool {
+		if len(results) == 0 {
+			return true
+		}
+		result := results[0]
+		results = results[1:]
+		return result
+	}
+}
+
+// newCassetteConfig returns a config whose client replays the cassette at
+// path without any network access. With -record the cassette is recorded
+// first instead.
+func newCassetteConfig(t *testing.T, path string) *config {
+	t.Helper()
+
+	var opts []pokeapi.Option
+	if *record {
+		baseURL := os.Getenv("POKEAPI_BASE_URL")
+		if baseURL == "" {
+			server := pokeapitest.NewServer()
+			t.Cleanup(server.Close)
+			baseURL = server.BaseURL()
+		}
+		// The cassette names the default base URL, which the replay uses.
+		recorder := httpreplay.NewRecorder(path, nil, httpreplay.WithRecordedBaseURL(baseURL, pokeapi.DefaultBaseURL))
+		opts = append(opts, pokeapi.WithBaseURL(baseURL), pokeapi.WithTransport(recorder))
+	} else {
+		replayer, err := httpreplay.LoadReplayer(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts = append(opts, pokeapi.WithTransport(replayer))
+	}
+
+	client := pokeapi.NewClient(time.Second, time.Minute, opts...)
+	t.Cleanup(client.Close)
+
+	return &config{
+		pokedex:       pokedex.New(),
+		pokeapiClient: client,
+		language:      defaultLanguage,
+		attempts:      map[string]int{},
+	}
+}
+
+// captureOutput runs fn and returns everything it printed to stdout.
+func captureOutput(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	fnErr := fn()
+	os.Stdout = stdout
+	w.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), fnErr
+}
+
+func TestCommandMapPaging(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	out, err := captureOutput(t, func() error { return commandMap(ctx, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "canalave-city-area\neterna-forest-area\n" {
+		t.Errorf("unexpected first page: %q", out)
+	}
+
+	out, err = captureOutput(t, func() error { return commandMap(ctx, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "pastoria-city-area\n" {
+		t.Errorf("unexpected second page: %q", out)
+	}
+
+	out, err = captureOutput(t, func() error { return commandMapb(ctx, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "canalave-city-area\neterna-forest-area\n" {
+		t.Errorf("unexpected previous page: %q", out)
+	}
+
+	if err := commandMapb(ctx, cfg); err == nil {
+		t.Errorf("expected error on first page")
+	}
+}
+
+func TestCommandExplore(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	out, err := captureOutput(t, func() error {
+		return commandExplore(context.Background(), cfg, "canalave-city-area")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Exploring canalave-city-area...", "- tentacool", "- pikachu"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+}
+
+func TestCommandExploreUnknownLocation(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	err := commandExplore(context.Background(), cfg, "pallet-twn")
+	var notFound *pokeapi.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestCommandCatchAndInspect(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	if err := commandInspect(ctx, cfg, "pikachu"); err == nil {
+		t.Errorf("expected error inspecting uncaught pokemon")
+	}
+
+	out, err := captureOutput(t, func() error { return commandCatch(ctx, cfg, "pikachu") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.pokedex.Lookup("pikachu"); err != nil || !strings.Contains(out, "pikachu was caught!") {
+		t.Errorf("expected pikachu to be caught: %v, output %q", err, out)
+	}
+
+	tentacool, err := cfg.pokeapiClient.CatchPokemon("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caught := pokedex.FromAPI(tentacool)
+	caught.Level = 12
+	caught.Stats[4].IV = 31
+	caught.CaughtAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	caught.Location = "pastoria-city-area"
+	caught.Attempts = 3
+	cfg.pokedex.Add(caught)
+	out, err = captureOutput(t, func() error { return commandInspect(ctx, cfg, "tentacool") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Name: tentacool", "Level: 12", "Height: 9", "-special-defense: 32 (base 100, iv 31)", "-poison",
+		"Species: Jellyfish Pokémon",
+		"Pokedex entry: Drifts in shallow seas. Anglers who hook them",
+		"Caught: 2024-05-01 12:00:00 at pastoria-city-area after 3 attempts",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+}
+
+func TestCommandCache(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	if err := commandExplore(ctx, cfg, "canalave-city-area"); err != nil {
+		t.Fatal(err)
+	}
+	if err := commandExplore(ctx, cfg, "canalave-city-area"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureOutput(t, func() error { return commandCache(ctx, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Hits: 1", "Misses: 1", "Entries: 1", "/location-area/canalave-city-area"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+
+	key := cfg.pokeapiClient.Cache().Entries()[0].Key
+	if err := commandCache(ctx, cfg, "evict", key); err != nil {
+		t.Fatal(err)
+	}
+	if err := commandCache(ctx, cfg, "evict", key); err == nil {
+		t.Errorf("expected error evicting uncached key")
+	}
+
+	if err := commandCache(ctx, cfg, "clear"); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cfg.pokeapiClient.Cache().Stats(); stats.Entries != 0 {
+		t.Errorf("expected empty cache, got %+v", stats)
+	}
+}
+
+func TestCommandOffline(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	out, err := captureOutput(t, func() error { return commandOffline(ctx, cfg, "on") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Offline mode is on\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	err = commandExplore(ctx, cfg, "canalave-city-area")
+	var offlineErr *pokeapi.OfflineError
+	if !errors.As(err, &offlineErr) {
+		t.Errorf("expected OfflineError, got %v", err)
+	}
+
+	if err := commandOffline(ctx, cfg, "sideways"); err == nil {
+		t.Errorf("expected error for unknown mode")
+	}
+	if _, err := captureOutput(t, func() error { return commandOffline(ctx, cfg, "off") }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureOutput(t, func() error { return commandExplore(ctx, cfg, "canalave-city-area") }); err != nil {
+		t.Errorf("expected explore to work online again, got %v", err)
+	}
+}
+
+func TestCommandPrefetch(t *testing.T) {
+	cases := []struct {
+		target string
+		areas  []string
+	}{
+		{
+			target: "sinnoh",
+			areas:  []string{"canalave-city-area", "eterna-forest-area"},
+		},
+		{
+			target: "all",
+			areas:  []string{"canalave-city-area", "eterna-forest-area", "pastoria-city-area"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			ctx := context.Background()
+
+			out, err := captureOutput(t, func() error { return commandPrefetch(ctx, cfg, c.target) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := fmt.Sprintf("Prefetched %d location areas and 2 pokemon", len(c.areas))
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in output %q", want, out)
+			}
+
+			// Everything needed to explore and catch must now work offline.
+			cfg.pokeapiClient.SetOffline(true)
+			for _, area := range c.areas {
+				if _, err := cfg.pokeapiClient.GetLocationArea(area); err != nil {
+					t.Errorf("expected %s to be cached: %v", area, err)
+				}
+			}
+			for _, name := range []string{"pikachu", "tentacool"} {
+				if _, err := cfg.pokeapiClient.CatchPokemon(name); err != nil {
+					t.Errorf("expected %s to be cached: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandPrefetchReportsMissingLocations(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	// The fake PokeAPI has no fixture for hoenn's only location.
+	out, err := captureOutput(t, func() error { return commandPrefetch(context.Background(), cfg, "hoenn") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Prefetched 0 location areas and 0 pokemon\n",
+		"1 resources could not be fetched:\n  - ",
+		"location/littleroot-town",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+}
+
+func TestCommandPrefetchUnknownRegion(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	err := commandPrefetch(context.Background(), cfg, "atlantis")
+	var notFound *pokeapi.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	cfg := newCassetteConfig(t, "testdata/session.cassette.json")
+	ctx := context.Background()
+
+	session := []struct {
+		command func(context.Context, *config, ...string) error
+		args    []string
+		want    string
+	}{
+		{command: commandMap, want: "canalave-city-area\neterna-forest-area\n"},
+		{command: commandMap, want: "pastoria-city-area\n"},
+		{command: commandMapb, want: "canalave-city-area\neterna-forest-area\n"},
+		{command: commandExplore, args: []string{"eterna-forest-area"}, want: "- pikachu"},
+		{command: commandPrefetch, args: []string{"sinnoh"}, want: "Prefetched 2 location areas and 2 pokemon"},
+	}
+	for _, step := range session {
+		out, err := captureOutput(t, func() error { return step.command(ctx, cfg, step.args...) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, step.want) {
+			t.Errorf("expected %q in output %q", step.want, out)
+		}
+	}
+}
+
+func TestCommandInspectLocalized(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+
+	cases := []struct {
+		language string
+		want     []string
+	}{
+		{language: "fr", want: []string{"Species: Pokémon démo", "Pokedex entry: Texte de démonstration."}},
+		// No Japanese text in the fixture, so English is shown instead.
+		{language: "ja", want: []string{"Species: Mouse Pokémon", "Pokedex entry: When several of these POKéMON gather"}},
+	}
+	for _, c := range cases {
+		cfg.language = c.language
+		out, err := captureOutput(t, func() error { return commandInspect(ctx, cfg, "pikachu") })
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range c.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: expected %q in output %q", c.language, want, out)
+			}
+		}
+	}
+}
+
+func TestCommandEvolutions(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	out, err := captureOutput(t, func() error {
+		return commandEvolutions(context.Background(), cfg, "pikachu")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "pichu\n" +
+		"  -> pikachu (level up with happiness 220)\n" +
+		"    -> raichu (use thunder-stone)\n"
+	if out != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+}
+
+func TestCommandMoveAbilityType(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	cases := []struct {
+		name     string
+		command  func(context.Context, *config, ...string) error
+		arg      string
+		expected []string
+	}{
+		{
+			name:    "move",
+			command: commandMove,
+			arg:     "thunder-shock",
+			expected: []string{
+				"Type: electric\n",
+				"Damage class: special\n",
+				"Power: 40\n",
+				"Accuracy: 100\n",
+				"PP: 30\n",
+				"Effect: Has a 10% chance to paralyze the target.\n",
+			},
+		},
+		{
+			name:     "status move",
+			command:  commandMove,
+			arg:      "thunder-wave",
+			expected: []string{"Power: -\n", "Accuracy: 90\n"},
+		},
+		{
+			name:    "ability",
+			command: commandAbility,
+			arg:     "static",
+			expected: []string{
+				"Effect: Has a 30% chance of paralyzing attacking Pokémon on contact.\n",
+				"  -pikachu\n",
+				"  -pichu (hidden)\n",
+			},
+		},
+		{
+			name:    "type",
+			command: commandType,
+			arg:     "electric",
+			expected: []string{
+				"  -double damage to: flying, water\n",
+				"  -no damage to: ground\n",
+				"  -double damage from: ground\n",
+				"  -no damage from: none\n",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := captureOutput(t, func() error {
+				return c.command(context.Background(), cfg, c.arg)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range c.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandNicknameNoteRelease(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+	cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+
+	if err := commandNickname(ctx, cfg, "pikachu", "Sparky"); err == nil {
+		t.Error("expected error nicknaming one of two pikachu by name")
+	}
+	if err := commandNickname(ctx, cfg, "1", "42"); err == nil {
+		t.Error("expected error for a numeric nickname")
+	}
+
+	steps := []struct {
+		command func(context.Context, *config, ...string) error
+		args    []string
+		want    string
+	}{
+		{command: commandNickname, args: []string{"1", "Sparky"}, want: "#1 pikachu is now called Sparky\n"},
+		{command: commandNote, args: []string{"sparky", "Caught", "on", "my", "birthday"}, want: "Noted on #1 Sparky (pikachu)\n"},
+		{command: commandPokedex, want: "  - pikachu x2\n    #1 Sparky\n      note: Caught on my birthday\n    #2\n"},
+		{command: commandRelease, args: []string{"2"}, want: "#2 pikachu was released\n"},
+		{command: commandNote, args: []string{"pikachu"}, want: "Cleared the note on #1 Sparky (pikachu)\n"},
+	}
+	for _, step := range steps {
+		out, err := captureOutput(t, func() error { return step.command(ctx, cfg, step.args...) })
+		if err != nil {
+			t.Fatalf("%v: %v", step.args, err)
+		}
+		if !strings.Contains(out, step.want) {
+			t.Errorf("%v: expected %q in output %q", step.args, step.want, out)
+		}
+	}
+
+	if _, err := cfg.pokedex.Lookup("sparky"); err != nil {
+		t.Errorf("expected to find Sparky after the release: %v", err)
+	}
+	if err := commandRelease(ctx, cfg, "2"); err == nil {
+		t.Error("expected error releasing a pokemon twice")
+	}
+
+	// Nicknames must stay usable as a reference to a single pokemon.
+	third := cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+	ref := strconv.Itoa(third.ID)
+	if err := commandNickname(ctx, cfg, ref, "Mr", "Sparky"); err == nil {
+		t.Error("expected error for a nickname of several words")
+	}
+	if err := commandNickname(ctx, cfg, ref, "SPARKY"); err == nil {
+		t.Error("expected error for a nickname another pokemon has")
+	}
+	if _, err := captureOutput(t, func() error { return commandNickname(ctx, cfg, "sparky", "Sparky") }); err != nil {
+		t.Errorf("expected renaming a pokemon to its own nickname to work: %v", err)
+	}
+}
+
+func TestCommandInspectNicknameAndNote(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caught := pokedex.FromAPI(pokemon)
+	caught.Nickname = "Squishy"
+	caught.Note = "Stings"
+	cfg.pokedex.Add(caught)
+
+	out, err := captureOutput(t, func() error { return commandInspect(ctx, cfg, "squishy") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ID: 1\n", "Name: tentacool\n", "Nickname: Squishy\n", "Note: Stings\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+}
+
+func TestCommandCatchKeepsEveryIndividual(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	// The second tentacool escapes the first pokeball thrown at it.
+	cfg.catchRoll = catchRolls(true, false, true)
+	for i := 0; i < 3; i++ {
+		if _, err := captureOutput(t, func() error { return commandCatch(ctx, cfg, "tentacool") }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	caught := cfg.pokedex.All()
+	if len(caught) != 2 {
+		t.Fatalf("expected two tentacool, got %+v", caught)
+	}
+	if caught[0].ID == caught[1].ID {
+		t.Errorf("expected distinct ids, got %d twice", caught[0].ID)
+	}
+	if caught[0].Attempts != 1 || caught[1].Attempts != 2 {
+		t.Errorf("expected 1 and 2 attempts, got %d and %d", caught[0].Attempts, caught[1].Attempts)
+	}
+	for _, p := range caught {
+		if p.Level < pokedex.MinWildLevel || p.Level > pokedex.MaxWildLevel {
+			t.Errorf("#%d: level %d outside the wild range", p.ID, p.Level)
+		}
+	}
+
+	out, err := captureOutput(t, func() error { return commandPokedex(ctx, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Your Pokedex (2 pokemon of 1 species):\n  - tentacool x2\n"; !strings.HasPrefix(out, want) {
+		t.Errorf("expected output to start with %q, got %q", want, out)
+	}
+
+	if err := commandInspect(ctx, cfg, "tentacool"); err == nil {
+		t.Error("expected error inspecting one of two tentacool by name")
+	}
+	out, err = captureOutput(t, func() error { return commandInspect(ctx, cfg, strconv.Itoa(caught[1].ID)) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("ID: %d\n", caught[1].ID); !strings.Contains(out, want) {
+		t.Errorf("expected %q in output %q", want, out)
+	}
+}
+
+func TestCommandReleaseNicknameMatchingSpecies(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"pikachu", "tentacool"} {
+		pokemon, err := cfg.pokeapiClient.CatchPokemon(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+	}
+	if _, err := captureOutput(t, func() error { return commandNickname(ctx, cfg, "pikachu", "tentacool") }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := commandRelease(ctx, cfg, "tentacool")
+	var ambiguous *pokedex.AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected ambiguous error, got %v", err)
+	}
+	if cfg.pokedex.Len() != 2 {
+		t.Errorf("expected nothing to be released, got %v", cfg.pokedex.All())
+	}
+}
@@ -0,0 +1,123 @@
+package httpreplay
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(r.URL.RequestURI() + strconv.Itoa(int(n))))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recording := &http.Client{Transport: NewRecorder(path, nil)}
+	get(t, recording, server.URL+"/pokemon/pikachu")
+	get(t, recording, server.URL+"/pokemon/pikachu")
+	get(t, recording, server.URL+"/missing")
+	server.Close()
+
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaying := &http.Client{Transport: replayer}
+
+	// Matching ignores the host, so any base URL replays the cassette.
+	cases := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{url: "https://pokeapi.example/pokemon/pikachu", status: http.StatusOK, body: "/pokemon/pikachu1"},
+		{url: "https://pokeapi.example/pokemon/pikachu", status: http.StatusOK, body: "/pokemon/pikachu2"},
+		{url: "https://pokeapi.example/pokemon/pikachu", status: http.StatusOK, body: "/pokemon/pikachu2"},
+		{url: "https://pokeapi.example/missing", status: http.StatusNotFound, body: "404 page not found\n"},
+	}
+	for _, c := range cases {
+		status, body := get(t, replaying, c.url)
+		if status != c.status || body != c.body {
+			t.Errorf("GET %s: expected %d %q, got %d %q", c.url, c.status, c.body, status, body)
+		}
+	}
+
+	if _, err := replaying.Get("https://pokeapi.example/pokemon/mew"); err == nil {
+		t.Errorf("expected error for unrecorded request")
+	}
+}
+
+func TestRecordedBaseURL(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", server.URL+"/pokemon/25")
+		w.Write([]byte(`{"next": "` + server.URL + `/pokemon/raichu"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recording := &http.Client{Transport: NewRecorder(path, nil, WithRecordedBaseURL(server.URL, "https://pokeapi.example"))}
+	if _, body := get(t, recording, server.URL+"/pokemon/pikachu"); !strings.Contains(body, server.URL) {
+		t.Errorf("expected the live response to be left alone, got %q", body)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cassette.Interactions[0]
+	if got.URL != "https://pokeapi.example/pokemon/pikachu" {
+		t.Errorf("unexpected URL %q", got.URL)
+	}
+	if want := `{"next": "https://pokeapi.example/pokemon/raichu"}`; got.Body != want {
+		t.Errorf("expected body %q, got %q", want, got.Body)
+	}
+	if location := got.Header.Get("Location"); location != "https://pokeapi.example/pokemon/25" {
+		t.Errorf("unexpected Location %q", location)
+	}
+}
+
+func TestRecordedBaseURLTrailingSlash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewRecorder(path, nil, WithRecordedBaseURL(server.URL+"/api/v2/", "https://pokeapi.example/api/v2"))
+	get(t, &http.Client{Transport: recorder}, server.URL+"/api/v2/pokemon/pikachu")
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cassette.Interactions[0].URL, "https://pokeapi.example/api/v2/pokemon/pikachu"; got != want {
+		t.Errorf("expected URL %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,101 @@
+package httpreplay
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper that forwards requests to another
+// transport and appends every response to a cassette file.
+type Recorder struct {
+	path string
+	next http.RoundTripper
+	// rewrite maps the base URL requests are sent to onto the one recorded.
+	rewrite *strings.Replacer
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// RecorderOption configures a Recorder in NewRecorder.
+type RecorderOption func(*Recorder)
+
+// WithRecordedBaseURL records every URL under from as if it were under to,
+// both in request URLs and in response bodies and headers. A cassette
+// recorded against a local server or a mirror then names a single host.
+// Trailing slashes are ignored, as in pokeapi.WithBaseURL.
+func WithRecordedBaseURL(from, to string) RecorderOption {
+	from = strings.TrimRight(from, "/")
+	to = strings.TrimRight(to, "/")
+	return func(r *Recorder) {
+		if from != to {
+			r.rewrite = strings.NewReplacer(from, to)
+		}
+	}
+}
+
+// NewRecorder records into the cassette at path, replacing any existing
+// file. A nil next uses http.DefaultTransport.
+func NewRecorder(path string, next http.RoundTripper, opts ...RecorderOption) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		path: path,
+		next: next,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RoundTrip performs the request and records the interaction. The cassette
+// is saved after every interaction, so nothing is lost if the process exits
+// abruptly.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := res.Header.Clone()
+	// The body is stored decoded, so length and encoding no longer apply.
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+
+	interaction := Interaction{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: res.StatusCode,
+		Header:     header,
+		Body:       string(body),
+	}
+	if r.rewrite != nil {
+		interaction.URL = r.rewrite.Replace(interaction.URL)
+		interaction.Body = r.rewrite.Replace(interaction.Body)
+		for _, values := range interaction.Header {
+			for i, value := range values {
+				values[i] = r.rewrite.Replace(value)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.cassette.Save(r.path); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
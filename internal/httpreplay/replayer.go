@@ -0,0 +1,77 @@
+package httpreplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNotRecorded is returned by Replayer for requests missing from its
+// cassette. Retrying them cannot succeed.
+var ErrNotRecorded = errors.New("httpreplay: no recorded response")
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// and never touches the network. Requests recorded several times are
+// replayed in order, repeating the last response once exhausted.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+// NewReplayer returns a Replayer serving the interactions in cassette.
+func NewReplayer(cassette Cassette) *Replayer {
+	r := &Replayer{
+		interactions: make(map[string][]Interaction),
+		served:       make(map[string]int),
+	}
+	for _, interaction := range cassette.Interactions {
+		key := matchKey(interaction.Method, interaction.URL)
+		r.interactions[key] = append(r.interactions[key], interaction)
+	}
+	return r
+}
+
+// LoadReplayer reads the cassette at path and returns a Replayer for it.
+func LoadReplayer(path string) (*Replayer, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(cassette), nil
+}
+
+// RoundTrip returns the recorded response for req, or an error matching
+// ErrNotRecorded if the request was never recorded.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL.String())
+
+	r.mu.Lock()
+	recorded := r.interactions[key]
+	if len(recorded) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, req.URL)
+	}
+	interaction := recorded[min(r.served[key], len(recorded)-1)]
+	r.served[key]++
+	r.mu.Unlock()
+
+	header := interaction.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
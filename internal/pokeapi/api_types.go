@@ -1,5 +1,44 @@
 package pokeapi
 
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// NamedAPIResource is PokeAPI's reference to another resource.
+type NamedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ResourceID returns the numeric id at the end of a PokeAPI resource URL,
+// e.g. 10 for https://pokeapi.co/api/v2/evolution-chain/10/.
+func ResourceID(resourceURL string) (int, error) {
+	id, err := strconv.Atoi(path.Base(strings.TrimRight(resourceURL, "/")))
+	if err != nil {
+		return 0, errors.New("no resource id in url " + resourceURL)
+	}
+	return id, nil
+}
+
+// Region is a /region resource, e.g. kanto or sinnoh.
+type Region struct {
+	ID        int                `json:"id"`
+	Name      string             `json:"name"`
+	Locations []NamedAPIResource `json:"locations"`
+}
+
+// Location is a /location resource; its areas are the LocationArea resources.
+type Location struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Region NamedAPIResource   `json:"region"`
+	Areas  []NamedAPIResource `json:"areas"`
+}
+
 type LocationAreas struct {
 	Count    int     `json:"count"`
 	Next     *string `json:"next"`
@@ -307,3 +346,247 @@ type Pokemon struct {
 	} `json:"types"`
 	Weight int `json:"weight"`
 }
+
+// PokemonSpecies is a /pokemon-species resource: the data shared by all
+// forms of a pokemon, such as its Pokedex entries and evolution chain.
+type PokemonSpecies struct {
+	ID                 int               `json:"id"`
+	Name               string            `json:"name"`
+	Order              int               `json:"order"`
+	CaptureRate        int               `json:"capture_rate"`
+	BaseHappiness      int               `json:"base_happiness"`
+	IsBaby             bool              `json:"is_baby"`
+	IsLegendary        bool              `json:"is_legendary"`
+	IsMythical         bool              `json:"is_mythical"`
+	GrowthRate         NamedAPIResource  `json:"growth_rate"`
+	EvolvesFromSpecies *NamedAPIResource `json:"evolves_from_species"`
+	EvolutionChain     struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	Genera []struct {
+		Genus    string           `json:"genus"`
+		Language NamedAPIResource `json:"language"`
+	} `json:"genera"`
+	FlavorTextEntries []struct {
+		FlavorText string           `json:"flavor_text"`
+		Language   NamedAPIResource `json:"language"`
+		Version    NamedAPIResource `json:"version"`
+	} `json:"flavor_text_entries"`
+}
+
+// Genus returns the species' genus, e.g. "Mouse Pokémon", in the given
+// language, or an empty string if there is none.
+func (s PokemonSpecies) Genus(language string) string {
+	for _, genus := range s.Genera {
+		if genus.Language.Name == language {
+			return genus.Genus
+		}
+	}
+	return ""
+}
+
+// FlavorText returns the most recent Pokedex entry in the given language,
+// with the line and page breaks of the game text collapsed into spaces, or
+// an empty string if there is none.
+func (s PokemonSpecies) FlavorText(language string) string {
+	for i := len(s.FlavorTextEntries) - 1; i >= 0; i-- {
+		entry := s.FlavorTextEntries[i]
+		if entry.Language.Name == language {
+			return strings.Join(strings.Fields(entry.FlavorText), " ")
+		}
+	}
+	return ""
+}
+
+// EvolutionChain is an /evolution-chain resource.
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+// ChainLink is a species in an evolution chain, the conditions under which
+// it evolves from its parent, and the species it evolves into.
+type ChainLink struct {
+	IsBaby           bool              `json:"is_baby"`
+	Species          NamedAPIResource  `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+// EvolutionDetail is one way of triggering an evolution. Unset conditions
+// are nil or zero.
+type EvolutionDetail struct {
+	Trigger               NamedAPIResource  `json:"trigger"`
+	Item                  *NamedAPIResource `json:"item"`
+	HeldItem              *NamedAPIResource `json:"held_item"`
+	KnownMove             *NamedAPIResource `json:"known_move"`
+	KnownMoveType         *NamedAPIResource `json:"known_move_type"`
+	Location              *NamedAPIResource `json:"location"`
+	PartySpecies          *NamedAPIResource `json:"party_species"`
+	PartyType             *NamedAPIResource `json:"party_type"`
+	TradeSpecies          *NamedAPIResource `json:"trade_species"`
+	Gender                *int              `json:"gender"`
+	MinLevel              *int              `json:"min_level"`
+	MinHappiness          *int              `json:"min_happiness"`
+	MinBeauty             *int              `json:"min_beauty"`
+	MinAffection          *int              `json:"min_affection"`
+	RelativePhysicalStats *int              `json:"relative_physical_stats"`
+	NeedsOverworldRain    bool              `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool              `json:"turn_upside_down"`
+	TimeOfDay             string            `json:"time_of_day"`
+}
+
+// String describes the evolution conditions, e.g. "level 30" or
+// "use thunder-stone".
+func (d EvolutionDetail) String() string {
+	var parts []string
+	switch d.Trigger.Name {
+	case "level-up":
+		if d.MinLevel != nil {
+			parts = append(parts, fmt.Sprintf("level %d", *d.MinLevel))
+		} else {
+			parts = append(parts, "level up")
+		}
+	case "use-item":
+		if d.Item != nil {
+			parts = append(parts, "use "+d.Item.Name)
+		} else {
+			parts = append(parts, "use item")
+		}
+	case "trade":
+		parts = append(parts, "trade")
+		if d.TradeSpecies != nil {
+			parts = append(parts, "for "+d.TradeSpecies.Name)
+		}
+	default:
+		parts = append(parts, d.Trigger.Name)
+	}
+
+	if d.HeldItem != nil {
+		parts = append(parts, "holding "+d.HeldItem.Name)
+	}
+	if d.MinHappiness != nil {
+		parts = append(parts, fmt.Sprintf("with happiness %d", *d.MinHappiness))
+	}
+	if d.MinAffection != nil {
+		parts = append(parts, fmt.Sprintf("with affection %d", *d.MinAffection))
+	}
+	if d.MinBeauty != nil {
+		parts = append(parts, fmt.Sprintf("with beauty %d", *d.MinBeauty))
+	}
+	if d.KnownMove != nil {
+		parts = append(parts, "knowing "+d.KnownMove.Name)
+	}
+	if d.KnownMoveType != nil {
+		parts = append(parts, "knowing a "+d.KnownMoveType.Name+" move")
+	}
+	if d.Location != nil {
+		parts = append(parts, "at "+d.Location.Name)
+	}
+	if d.PartySpecies != nil {
+		parts = append(parts, "with "+d.PartySpecies.Name+" in party")
+	}
+	if d.PartyType != nil {
+		parts = append(parts, "with a "+d.PartyType.Name+" pokemon in party")
+	}
+	if d.TimeOfDay != "" {
+		parts = append(parts, "during "+d.TimeOfDay)
+	}
+	if d.NeedsOverworldRain {
+		parts = append(parts, "while raining")
+	}
+	if d.TurnUpsideDown {
+		parts = append(parts, "with the console upside down")
+	}
+	return strings.Join(parts, " ")
+}
+
+// VerboseEffect is the description of a move's or ability's effect in one
+// language.
+type VerboseEffect struct {
+	Effect      string           `json:"effect"`
+	ShortEffect string           `json:"short_effect"`
+	Language    NamedAPIResource `json:"language"`
+}
+
+// shortEffect returns the short effect text in the given language, or an
+// empty string if there is none.
+func shortEffect(entries []VerboseEffect, language string) string {
+	for _, entry := range entries {
+		if entry.Language.Name == language {
+			return strings.Join(strings.Fields(entry.ShortEffect), " ")
+		}
+	}
+	return ""
+}
+
+// Move is a /move resource. Power, Accuracy and PP are nil for moves that
+// have no such value, e.g. the power of a status move.
+type Move struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	Power         *int             `json:"power"`
+	Accuracy      *int             `json:"accuracy"`
+	PP            *int             `json:"pp"`
+	Priority      int              `json:"priority"`
+	EffectChance  *int             `json:"effect_chance"`
+	Type          NamedAPIResource `json:"type"`
+	DamageClass   NamedAPIResource `json:"damage_class"`
+	Target        NamedAPIResource `json:"target"`
+	EffectEntries []VerboseEffect  `json:"effect_entries"`
+}
+
+// Effect returns the move's short effect in the given language, with the
+// effect chance filled in, or an empty string if there is none.
+func (m Move) Effect(language string) string {
+	effect := shortEffect(m.EffectEntries, language)
+	if m.EffectChance != nil {
+		effect = strings.ReplaceAll(effect, "$effect_chance", strconv.Itoa(*m.EffectChance))
+	}
+	return effect
+}
+
+// Ability is an /ability resource.
+type Ability struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	IsMainSeries  bool             `json:"is_main_series"`
+	Generation    NamedAPIResource `json:"generation"`
+	EffectEntries []VerboseEffect  `json:"effect_entries"`
+	Pokemon       []struct {
+		IsHidden bool             `json:"is_hidden"`
+		Slot     int              `json:"slot"`
+		Pokemon  NamedAPIResource `json:"pokemon"`
+	} `json:"pokemon"`
+}
+
+// Effect returns the ability's short effect in the given language, or an
+// empty string if there is none.
+func (a Ability) Effect(language string) string {
+	return shortEffect(a.EffectEntries, language)
+}
+
+// Type is a /type resource, e.g. electric or water.
+type Type struct {
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	Generation      NamedAPIResource   `json:"generation"`
+	MoveDamageClass *NamedAPIResource  `json:"move_damage_class"`
+	DamageRelations TypeRelations      `json:"damage_relations"`
+	Moves           []NamedAPIResource `json:"moves"`
+	Pokemon         []struct {
+		Slot    int              `json:"slot"`
+		Pokemon NamedAPIResource `json:"pokemon"`
+	} `json:"pokemon"`
+}
+
+// TypeRelations lists the types a type deals double, half or no damage to,
+// and takes double, half or no damage from.
+type TypeRelations struct {
+	DoubleDamageTo   []NamedAPIResource `json:"double_damage_to"`
+	HalfDamageTo     []NamedAPIResource `json:"half_damage_to"`
+	NoDamageTo       []NamedAPIResource `json:"no_damage_to"`
+	DoubleDamageFrom []NamedAPIResource `json:"double_damage_from"`
+	HalfDamageFrom   []NamedAPIResource `json:"half_damage_from"`
+	NoDamageFrom     []NamedAPIResource `json:"no_damage_from"`
+}
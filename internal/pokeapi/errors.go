@@ -0,0 +1,121 @@
+package pokeapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NotFoundError is returned when PokeAPI has no resource at the requested URL,
+// usually because of a misspelled location or pokemon name.
+type NotFoundError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("not found: %s (status %d)", e.URL, e.StatusCode)
+}
+
+// RateLimitError is returned when PokeAPI answers with 429 Too Many Requests.
+// RetryAfter is the wait requested by the server, or zero if none was given.
+type RateLimitError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited: %s (status %d)", e.URL, e.StatusCode)
+}
+
+// StatusError is returned for any other non-2xx response.
+// RetryAfter is the wait requested by the server, or zero if none was given.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.URL)
+}
+
+// TransportError wraps failures to reach PokeAPI or read its response.
+type TransportError struct {
+	URL string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("request to %s failed: %v", e.URL, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError wraps failures to parse a PokeAPI response body.
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding response from %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// OfflineError is returned in offline mode for resources missing from the cache.
+type OfflineError struct {
+	URL string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("not available offline: %s", e.URL)
+}
+
+// RetryError is returned when a request still fails after retrying.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// statusError maps a non-2xx response to the matching error type.
+func statusError(url string, res *http.Response) error {
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return &NotFoundError{URL: url, StatusCode: res.StatusCode}
+	case http.StatusTooManyRequests:
+		return &RateLimitError{URL: url, StatusCode: res.StatusCode, RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"))}
+	default:
+		return &StatusError{URL: url, StatusCode: res.StatusCode, RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"))}
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date. It returns zero when the header is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package pokeapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokeapitest"
+)
+
+func TestGetEvolutionChain(t *testing.T) {
+	server := pokeapitest.NewServer()
+	defer server.Close()
+	client := pokeapi.NewClient(time.Second, time.Minute, pokeapi.WithBaseURL(server.BaseURL()))
+	defer client.Close()
+
+	species, err := client.GetPokemonSpecies("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID, err := pokeapi.ResourceID(species.EvolutionChain.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := client.GetEvolutionChain(chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain.Chain.Species.Name != "tentacool" || len(chain.Chain.EvolvesTo) != 1 {
+		t.Fatalf("unexpected chain root: %+v", chain.Chain)
+	}
+	next := chain.Chain.EvolvesTo[0]
+	if next.Species.Name != "tentacruel" || len(next.EvolutionDetails) != 1 {
+		t.Fatalf("unexpected evolution: %+v", next)
+	}
+	if got := next.EvolutionDetails[0].String(); got != "level 30" {
+		t.Errorf("expected \"level 30\", got %q", got)
+	}
+}
+
+func TestEvolutionDetailString(t *testing.T) {
+	level, happiness := 20, 160
+	cases := []struct {
+		detail   pokeapi.EvolutionDetail
+		expected string
+	}{
+		{
+			detail:   pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: &level},
+			expected: "level 20",
+		},
+		{
+			detail: pokeapi.EvolutionDetail{
+				Trigger:      pokeapi.NamedAPIResource{Name: "level-up"},
+				MinHappiness: &happiness,
+				TimeOfDay:    "night",
+			},
+			expected: "level up with happiness 160 during night",
+		},
+		{
+			detail: pokeapi.EvolutionDetail{
+				Trigger:  pokeapi.NamedAPIResource{Name: "trade"},
+				HeldItem: &pokeapi.NamedAPIResource{Name: "metal-coat"},
+			},
+			expected: "trade holding metal-coat",
+		},
+		{
+			detail:   pokeapi.EvolutionDetail{Trigger: pokeapi.NamedAPIResource{Name: "shed"}},
+			expected: "shed",
+		},
+	}
+
+	for _, c := range cases {
+		if actual := c.detail.String(); actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, actual)
+		}
+	}
+}
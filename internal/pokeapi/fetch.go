@@ -0,0 +1,172 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokecache"
+)
+
+// fetch loads the resource at url, from the cache when possible, and decodes
+// it into T. New endpoints only need to build the URL and call fetch.
+// A zero ttl caches the response for the cache's default TTL.
+func fetch[T any](ctx context.Context, c *Client, url string, ttl time.Duration) (T, error) {
+	var resource T
+
+	body, err := c.get(ctx, url, ttl)
+	if err != nil {
+		return resource, err
+	}
+
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return resource, &DecodeError{URL: url, Err: err}
+	}
+	return resource, nil
+}
+
+// get returns the raw body at url, serving it from the cache when present
+// and caching successful network responses. An expired entry that carries
+// validators is revalidated with a conditional request, and may be served
+// as is according to the client's StalePolicy. In offline mode only the
+// cache is consulted.
+func (c *Client) get(ctx context.Context, url string, ttl time.Duration) ([]byte, error) {
+	cached, found := c.cache.Lookup(url)
+	if found && !cached.Expired() {
+		return cached.Val, nil
+	}
+
+	if c.Offline() {
+		if !found {
+			return nil, &OfflineError{URL: url}
+		}
+		markStale(ctx)
+		return cached.Val, nil
+	}
+
+	var stale *pokecache.Entry
+	if found {
+		stale = &cached
+		if c.stalePolicy == StaleWhileRevalidate {
+			markStale(ctx)
+			c.revalidate(url, ttl, stale)
+			return cached.Val, nil
+		}
+	}
+
+	body, err := c.flights.do(ctx, url, func(ctx context.Context) ([]byte, error) {
+		return c.download(ctx, url, ttl, stale)
+	})
+	if err != nil && ctx.Err() != nil {
+		return nil, &TransportError{URL: url, Err: ctx.Err()}
+	}
+	if _, ok := transient(err); ok && stale != nil && c.stalePolicy != StaleNever {
+		markStale(ctx)
+		return stale.Val, nil
+	}
+	return body, err
+}
+
+// response is the outcome of a single successful GET.
+type response struct {
+	body        []byte
+	validators  pokecache.Validators
+	notModified bool
+}
+
+// download fetches url, retrying transient failures according to the
+// client's RetryPolicy, and caches a valid response. When stale is not nil
+// its validators are sent, and a 304 Not Modified refreshes it in place.
+func (c *Client) download(ctx context.Context, url string, ttl time.Duration, stale *pokecache.Entry) ([]byte, error) {
+	var validators pokecache.Validators
+	if stale != nil {
+		validators = stale.Validators
+	}
+
+	var (
+		res response
+		err error
+	)
+	attempt := 1
+	for ; ; attempt++ {
+		res, err = c.doRequest(ctx, url, validators)
+		if err == nil || ctx.Err() != nil || attempt >= c.retry.MaxAttempts {
+			break
+		}
+		delay, ok := c.retry.delay(attempt, err)
+		if !ok {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, &TransportError{URL: url, Err: ctx.Err()}
+		}
+	}
+	if err != nil {
+		if attempt > 1 {
+			return nil, &RetryError{Attempts: attempt, Err: err}
+		}
+		return nil, err
+	}
+
+	if res.notModified {
+		c.cache.Refresh(url, ttl)
+		return stale.Val, nil
+	}
+	c.cache.AddWithValidators(url, res.body, ttl, res.validators)
+	return res.body, nil
+}
+
+// doRequest performs a single GET of url, made conditional when validators
+// are set, and returns the validated body.
+func (c *Client) doRequest(ctx context.Context, url string, validators pokecache.Validators) (response, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return response{}, &TransportError{URL: url, Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return response{}, &TransportError{URL: url, Err: err}
+	}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return response{}, &TransportError{URL: url, Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && validators != (pokecache.Validators{}) {
+		return response{notModified: true}, nil
+	}
+	if res.StatusCode > 299 {
+		return response{}, statusError(url, res)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return response{}, &TransportError{URL: url, Err: err}
+	}
+	if !json.Valid(body) {
+		return response{}, &DecodeError{URL: url, Err: errors.New("response is not valid JSON")}
+	}
+	return response{
+		body: body,
+		validators: pokecache.Validators{
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+		},
+	}, nil
+}
@@ -0,0 +1,249 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokecache"
+)
+
+func TestExpiredEntryIsRevalidated(t *testing.T) {
+	const etag = `"v1"`
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute,
+		WithBaseURL(server.URL),
+		WithResourceTTL(time.Millisecond),
+		WithCacheOptions(pokecache.WithStaleRetention(time.Hour)),
+	)
+	defer client.Close()
+
+	if _, err := client.CatchPokemon("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	pokemon, err := client.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pokemon.Name != "pikachu" {
+		t.Errorf("expected cached pikachu after 304, got %+v", pokemon)
+	}
+	if n := notModified.Load(); n != 1 {
+		t.Fatalf("expected 1 conditional request, got %d", n)
+	}
+
+	entry, ok := client.Cache().Lookup(server.URL + "/pokemon/pikachu")
+	if !ok || entry.Expired() {
+		t.Errorf("expected 304 to refresh the entry, got %+v", entry)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestExpiredEntryIsRevalidatedByDefault(t *testing.T) {
+	const etag = `"v1"`
+	var notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"count": 1, "results": [{"name": "canalave-city-area"}]}`))
+	}))
+	defer server.Close()
+
+	// Only the cache interval, which is also the TTL of list pages, is set.
+	client := NewClient(time.Second, 5*time.Millisecond)
+	defer client.Close()
+
+	url := server.URL + "/location-area"
+	for range 2 {
+		page, err := client.GetLocationAreas(&url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Results) != 1 {
+			t.Errorf("unexpected page: %+v", page)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := notModified.Load(); n != 1 {
+		t.Errorf("expected 1 conditional request, got %d", n)
+	}
+}
+
+func TestExpiredEntryWithoutValidatorsIsDownloaded(t *testing.T) {
+	var conditional atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditional.Add(1)
+		}
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute,
+		WithBaseURL(server.URL),
+		WithResourceTTL(time.Millisecond),
+		WithCacheOptions(pokecache.WithStaleRetention(time.Hour)),
+	)
+	defer client.Close()
+
+	for range 2 {
+		if _, err := client.CatchPokemon("pikachu"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := conditional.Load(); n != 0 {
+		t.Errorf("expected no conditional requests, got %d", n)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+			w.Write([]byte(`{"name": "pikachu", "base_experience": 2}`))
+			return
+		}
+		w.Write([]byte(`{"name": "pikachu", "base_experience": 1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute,
+		WithBaseURL(server.URL),
+		WithResourceTTL(time.Millisecond),
+		WithStalePolicy(StaleWhileRevalidate),
+		WithCacheOptions(pokecache.WithStaleRetention(time.Hour)),
+	)
+	defer client.Close()
+
+	if _, err := client.CatchPokemon("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The refresh is blocked on release, so this must be the stale copy.
+	var info FetchInfo
+	pokemon, err := client.CatchPokemonContext(WithFetchInfo(context.Background(), &info), "pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pokemon.BaseExperience != 1 || !info.Stale() {
+		t.Errorf("expected stale response, got %+v (stale=%v)", pokemon, info.Stale())
+	}
+
+	close(release)
+	client.background.Wait()
+
+	pokemon, err = client.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pokemon.BaseExperience != 2 {
+		t.Errorf("expected refreshed response, got %+v", pokemon)
+	}
+}
+
+func TestStaleIfError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute,
+		WithBaseURL(server.URL),
+		WithResourceTTL(time.Millisecond),
+		WithRetryPolicy(RetryPolicy{}),
+		WithStalePolicy(StaleIfError),
+		WithCacheOptions(pokecache.WithStaleRetention(time.Hour)),
+	)
+	defer client.Close()
+	if _, err := client.CatchPokemon("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	var info FetchInfo
+	pokemon, err := client.CatchPokemonContext(WithFetchInfo(context.Background(), &info), "pikachu")
+	if err != nil {
+		t.Fatalf("expected stale fallback, got %v", err)
+	}
+	if pokemon.Name != "pikachu" || !info.Stale() {
+		t.Errorf("expected stale pikachu, got %+v (stale=%v)", pokemon, info.Stale())
+	}
+
+	client.stalePolicy = StaleNever
+	if _, err := client.CatchPokemon("pikachu"); err == nil {
+		t.Errorf("expected error without a stale policy")
+	}
+}
+
+func TestOfflineMode(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute,
+		WithBaseURL(server.URL),
+		WithResourceTTL(time.Millisecond),
+		WithCacheOptions(pokecache.WithStaleRetention(time.Hour)),
+	)
+	defer client.Close()
+
+	if _, err := client.CatchPokemon("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	client.SetOffline(true)
+
+	var info FetchInfo
+	pokemon, err := client.CatchPokemonContext(WithFetchInfo(context.Background(), &info), "pikachu")
+	if err != nil {
+		t.Fatalf("expected expired entry offline, got %v", err)
+	}
+	if pokemon.Name != "pikachu" || !info.Stale() {
+		t.Errorf("expected stale pikachu, got %+v (stale=%v)", pokemon, info.Stale())
+	}
+
+	_, err = client.CatchPokemon("tentacool")
+	var offlineErr *OfflineError
+	if !errors.As(err, &offlineErr) {
+		t.Errorf("expected OfflineError, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected no requests while offline, got %d", n-1)
+	}
+}
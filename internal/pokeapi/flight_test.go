@@ -0,0 +1,109 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until n callers are waiting on the flight for key.
+func waitForWaiters(t *testing.T, g *flightGroup, key string, n int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		g.mu.Lock()
+		call, ok := g.calls[key]
+		waiting := ok && call.waiters == n
+		g.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiters on %s", n, key)
+}
+
+func TestConcurrentFetchesShareOneRequest(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute, WithBaseURL(server.URL))
+	defer client.Close()
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pokemon, err := client.CatchPokemon("pikachu")
+			if err == nil && pokemon.Name != "pikachu" {
+				err = errors.New("unexpected pokemon " + pokemon.Name)
+			}
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, client.flights, server.URL+"/pokemon/pikachu", callers)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestCancelledWaiterDoesNotCancelFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute, WithBaseURL(server.URL))
+	defer client.Close()
+	key := server.URL + "/pokemon/pikachu"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := client.CatchPokemonContext(ctx, "pikachu")
+		cancelled <- err
+	}()
+	waitForWaiters(t, client.flights, key, 1)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.CatchPokemon("pikachu")
+		result <- err
+	}()
+	waitForWaiters(t, client.flights, key, 2)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancelled caller to see context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("expected remaining caller to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package pokeapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokeapitest"
+)
+
+func TestGetMoveAbilityType(t *testing.T) {
+	server := pokeapitest.NewServer()
+	defer server.Close()
+	client := pokeapi.NewClient(time.Second, time.Minute, pokeapi.WithBaseURL(server.BaseURL()))
+	defer client.Close()
+
+	move, err := client.GetMove("thunder-shock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if move.Power == nil || *move.Power != 40 || move.DamageClass.Name != "special" {
+		t.Errorf("unexpected move: %+v", move)
+	}
+	if got := move.Effect("en"); got != "Has a 10% chance to paralyze the target." {
+		t.Errorf("unexpected effect %q", got)
+	}
+
+	ability, err := client.GetAbility("static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ability.Effect("fr"); got != "Peut paralyser au contact." {
+		t.Errorf("unexpected ability effect %q", got)
+	}
+	if got := ability.Effect("ja"); got != "" {
+		t.Errorf("expected no ja effect, got %q", got)
+	}
+
+	electric, err := client.GetType("electric")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(electric.DamageRelations.NoDamageTo) != 1 || electric.DamageRelations.NoDamageTo[0].Name != "ground" {
+		t.Errorf("unexpected damage relations: %+v", electric.DamageRelations)
+	}
+
+	if _, err := client.GetMove(""); err == nil {
+		t.Error("expected error for empty move name")
+	}
+}
@@ -0,0 +1,74 @@
+package pokeapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokecache"
+)
+
+// Option configures a Client in NewClient.
+type Option func(*Client)
+
+// WithBaseURL points the client at a PokeAPI-compatible server other than
+// the public one, e.g. a self-hosted mirror or a local test server.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithCacheOptions passes options through to the client's pokecache.Cache,
+// e.g. pokecache.WithDisk for a persistent second tier.
+func WithCacheOptions(opts ...pokecache.Option) Option {
+	return func(c *Client) {
+		c.cacheOptions = append(c.cacheOptions, opts...)
+	}
+}
+
+// WithResourceTTL sets how long named resources are cached, overriding
+// DefaultResourceTTL.
+func WithResourceTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.resourceTTL = ttl
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithRateLimit limits network requests to rps per second with bursts of up
+// to burst requests. A non-positive rps disables rate limiting.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(rps, burst)
+	}
+}
+
+// WithStalePolicy sets when expired cache entries may be served. The default
+// is StaleNever.
+func WithStalePolicy(policy StalePolicy) Option {
+	return func(c *Client) {
+		c.stalePolicy = policy
+	}
+}
+
+// WithOffline starts the client in offline mode, see Client.SetOffline.
+func WithOffline(offline bool) Option {
+	return func(c *Client) {
+		c.offline.Store(offline)
+	}
+}
+
+// WithTransport replaces the HTTP transport, e.g. with an httpreplay
+// Recorder or Replayer.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
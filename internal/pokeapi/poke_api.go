@@ -1,157 +1,230 @@
 package pokeapi
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"io"
-	"log"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/i-bielik/pokedexcli/internal/pokecache"
 )
 
 const (
-	baseURL = "https://pokeapi.co/api/v2"
+	// DefaultBaseURL is the public PokeAPI used unless WithBaseURL says otherwise.
+	DefaultBaseURL = "https://pokeapi.co/api/v2"
+
+	// DefaultResourceTTL is how long named resources such as a pokemon or a
+	// location area are cached. They practically never change, unlike the
+	// paginated lists, which use the cache's default TTL.
+	DefaultResourceTTL = 72 * time.Hour
+
+	// DefaultStaleRetention is how long expired responses stay cached so
+	// they can be revalidated with a conditional request, or served stale.
+	// WithCacheOptions(pokecache.WithStaleRetention(...)) overrides it.
+	DefaultStaleRetention = 30 * 24 * time.Hour
 )
 
 // Client -
 type Client struct {
-	cache      pokecache.Cache
+	cache      *pokecache.Cache
 	httpClient http.Client
+	baseURL    string
+
+	resourceTTL time.Duration
+	flights     *flightGroup
+	retry       RetryPolicy
+	limiter     *rateLimiter
+	stalePolicy StalePolicy
+	offline     *atomic.Bool
+
+	// backgroundCtx scopes stale-while-revalidate refreshes; Close cancels it
+	// and waits for them on background.
+	backgroundCtx  context.Context
+	stopBackground context.CancelFunc
+	background     *sync.WaitGroup
+
+	// cacheOptions are collected from Options and applied when NewClient
+	// builds the cache.
+	cacheOptions []pokecache.Option
 }
 
 // NewClient -
-func NewClient(timeout, cacheInterval time.Duration) Client {
-	return Client{
-		cache: pokecache.NewCache(cacheInterval),
+func NewClient(timeout, cacheInterval time.Duration, opts ...Option) Client {
+	c := Client{
 		httpClient: http.Client{
 			Timeout: timeout,
 		},
-	}
+		baseURL:     DefaultBaseURL,
+		resourceTTL: DefaultResourceTTL,
+		flights:     &flightGroup{},
+		retry:       DefaultRetryPolicy,
+		limiter:     newRateLimiter(DefaultRequestsPerSecond, DefaultBurst),
+		offline:     &atomic.Bool{},
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	// Later options win, so the default retention goes first.
+	cacheOpts := append([]pokecache.Option{pokecache.WithStaleRetention(DefaultStaleRetention)}, c.cacheOptions...)
+	c.cache = pokecache.NewCache(cacheInterval, cacheOpts...)
+	c.backgroundCtx, c.stopBackground = context.WithCancel(context.Background())
+	c.background = &sync.WaitGroup{}
+	return c
 }
 
-func (c *Client) GetLocationAreas(pageURL *string) (LocationAreas, error) {
-	// Simulate fetching location areas from an API
-	// In a real implementation, this would involve making an HTTP request to the PokeAPI
-	url := baseURL + "/location-area"
-	if pageURL != nil {
-		url = *pageURL
-	}
+// Close releases the client's background resources. The client must not be
+// used after Close.
+func (c *Client) Close() {
+	c.stopBackground()
+	c.background.Wait()
+	c.cache.Close()
+}
 
-	if cachedData, found := c.cache.Get(url); found {
-		var locations LocationAreas
-		err := json.Unmarshal(cachedData, &locations)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return locations, nil
-	}
+// SetOffline switches offline mode on or off. While offline the client never
+// touches the network and answers only from its cache, expired entries
+// included; anything else fails with an OfflineError.
+func (c *Client) SetOffline(offline bool) {
+	c.offline.Store(offline)
+}
 
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatal(err)
-	}
+// Offline reports whether offline mode is on.
+func (c *Client) Offline() bool {
+	return c.offline.Load()
+}
 
-	if res.StatusCode > 299 {
-		log.Fatalf("Response failed with status code: %d", res.StatusCode)
-	}
+// Cache exposes the client's response cache for inspection and maintenance.
+func (c *Client) Cache() *pokecache.Cache {
+	return c.cache
+}
 
-	body, err := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if err != nil {
-		log.Fatal(err)
-	}
-	locations := LocationAreas{}
-	err = json.Unmarshal(body, &locations)
-	if err != nil {
-		log.Fatal(err)
-	}
+// GetLocationAreas fetches a page of location areas; a nil pageURL fetches the first page.
+func (c *Client) GetLocationAreas(pageURL *string) (LocationAreas, error) {
+	return c.GetLocationAreasContext(context.Background(), pageURL)
+}
 
-	c.cache.Add(url, body)
-	return locations, nil
+// GetLocationAreasContext is like GetLocationAreas but aborts when ctx is done.
+func (c *Client) GetLocationAreasContext(ctx context.Context, pageURL *string) (LocationAreas, error) {
+	url := c.baseURL + "/location-area"
+	if pageURL != nil {
+		url = *pageURL
+	}
+	return fetch[LocationAreas](ctx, c, url, 0)
 }
 
+// GetLocationArea fetches a single location area by name.
 func (c *Client) GetLocationArea(locationName string) (LocationArea, error) {
-	// Simulate fetching location areas from an API
-	// In a real implementation, this would involve making an HTTP request to the PokeAPI
+	return c.GetLocationAreaContext(context.Background(), locationName)
+}
+
+// GetLocationAreaContext is like GetLocationArea but aborts when ctx is done.
+func (c *Client) GetLocationAreaContext(ctx context.Context, locationName string) (LocationArea, error) {
 	if locationName == "" {
 		return LocationArea{}, errors.New("location cannot be empty")
 	}
+	return fetch[LocationArea](ctx, c, c.baseURL+"/location-area/"+locationName, c.resourceTTL)
+}
 
-	url := baseURL + "/location-area/" + locationName
+// CatchPokemon fetches a pokemon by name.
+func (c *Client) CatchPokemon(pokemonName string) (Pokemon, error) {
+	return c.CatchPokemonContext(context.Background(), pokemonName)
+}
 
-	if cachedData, found := c.cache.Get(url); found {
-		var location LocationArea
-		err := json.Unmarshal(cachedData, &location)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return location, nil
+// CatchPokemonContext is like CatchPokemon but aborts when ctx is done.
+func (c *Client) CatchPokemonContext(ctx context.Context, pokemonName string) (Pokemon, error) {
+	if pokemonName == "" {
+		return Pokemon{}, errors.New("pokemon name cannot be empty")
 	}
+	return fetch[Pokemon](ctx, c, c.baseURL+"/pokemon/"+pokemonName, c.resourceTTL)
+}
 
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatal(err)
-	}
+// GetRegion fetches a region by name.
+func (c *Client) GetRegion(regionName string) (Region, error) {
+	return c.GetRegionContext(context.Background(), regionName)
+}
 
-	if res.StatusCode > 299 {
-		log.Fatalf("Response failed with status code: %d", res.StatusCode)
+// GetRegionContext is like GetRegion but aborts when ctx is done.
+func (c *Client) GetRegionContext(ctx context.Context, regionName string) (Region, error) {
+	if regionName == "" {
+		return Region{}, errors.New("region name cannot be empty")
 	}
+	return fetch[Region](ctx, c, c.baseURL+"/region/"+regionName, c.resourceTTL)
+}
 
-	body, err := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if err != nil {
-		log.Fatal(err)
-	}
-	var location LocationArea
-	err = json.Unmarshal(body, &location)
-	if err != nil {
-		log.Fatal(err)
+// GetLocation fetches a location by name.
+func (c *Client) GetLocation(locationName string) (Location, error) {
+	return c.GetLocationContext(context.Background(), locationName)
+}
+
+// GetLocationContext is like GetLocation but aborts when ctx is done.
+func (c *Client) GetLocationContext(ctx context.Context, locationName string) (Location, error) {
+	if locationName == "" {
+		return Location{}, errors.New("location name cannot be empty")
 	}
+	return fetch[Location](ctx, c, c.baseURL+"/location/"+locationName, c.resourceTTL)
+}
 
-	c.cache.Add(url, body)
-	return location, nil
+// GetPokemonSpecies fetches a pokemon species by name.
+func (c *Client) GetPokemonSpecies(speciesName string) (PokemonSpecies, error) {
+	return c.GetPokemonSpeciesContext(context.Background(), speciesName)
 }
 
-func (c *Client) CatchPokemon(pokemonName string) (Pokemon, error) {
-	// Simulate fetching location areas from an API
-	// In a real implementation, this would involve making an HTTP request to the PokeAPI
-	if pokemonName == "" {
-		return Pokemon{}, errors.New("pokemon name cannot be empty")
+// GetPokemonSpeciesContext is like GetPokemonSpecies but aborts when ctx is done.
+func (c *Client) GetPokemonSpeciesContext(ctx context.Context, speciesName string) (PokemonSpecies, error) {
+	if speciesName == "" {
+		return PokemonSpecies{}, errors.New("species name cannot be empty")
 	}
+	return fetch[PokemonSpecies](ctx, c, c.baseURL+"/pokemon-species/"+speciesName, c.resourceTTL)
+}
 
-	url := baseURL + "/pokemon/" + pokemonName
+// GetEvolutionChain fetches an evolution chain by id; see ResourceID for
+// getting it from PokemonSpecies.EvolutionChain.
+func (c *Client) GetEvolutionChain(chainID int) (EvolutionChain, error) {
+	return c.GetEvolutionChainContext(context.Background(), chainID)
+}
 
-	if cachedData, found := c.cache.Get(url); found {
-		var pokemon Pokemon
-		err := json.Unmarshal(cachedData, &pokemon)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return pokemon, nil
-	}
+// GetEvolutionChainContext is like GetEvolutionChain but aborts when ctx is done.
+func (c *Client) GetEvolutionChainContext(ctx context.Context, chainID int) (EvolutionChain, error) {
+	return fetch[EvolutionChain](ctx, c, c.baseURL+"/evolution-chain/"+strconv.Itoa(chainID), c.resourceTTL)
+}
 
-	res, err := http.Get(url)
-	if err != nil {
-		log.Fatal(err)
-	}
+// GetMove fetches a move by name.
+func (c *Client) GetMove(moveName string) (Move, error) {
+	return c.GetMoveContext(context.Background(), moveName)
+}
 
-	if res.StatusCode > 299 {
-		log.Fatalf("Response failed with status code: %d", res.StatusCode)
+// GetMoveContext is like GetMove but aborts when ctx is done.
+func (c *Client) GetMoveContext(ctx context.Context, moveName string) (Move, error) {
+	if moveName == "" {
+		return Move{}, errors.New("move name cannot be empty")
 	}
+	return fetch[Move](ctx, c, c.baseURL+"/move/"+moveName, c.resourceTTL)
+}
 
-	body, err := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if err != nil {
-		log.Fatal(err)
-	}
-	var pokemon Pokemon
-	err = json.Unmarshal(body, &pokemon)
-	if err != nil {
-		log.Fatal(err)
+// GetAbility fetches an ability by name.
+func (c *Client) GetAbility(abilityName string) (Ability, error) {
+	return c.GetAbilityContext(context.Background(), abilityName)
+}
+
+// GetAbilityContext is like GetAbility but aborts when ctx is done.
+func (c *Client) GetAbilityContext(ctx context.Context, abilityName string) (Ability, error) {
+	if abilityName == "" {
+		return Ability{}, errors.New("ability name cannot be empty")
 	}
+	return fetch[Ability](ctx, c, c.baseURL+"/ability/"+abilityName, c.resourceTTL)
+}
 
-	c.cache.Add(url, body)
-	return pokemon, nil
+// GetType fetches a type by name.
+func (c *Client) GetType(typeName string) (Type, error) {
+	return c.GetTypeContext(context.Background(), typeName)
+}
+
+// GetTypeContext is like GetType but aborts when ctx is done.
+func (c *Client) GetTypeContext(ctx context.Context, typeName string) (Type, error) {
+	if typeName == "" {
+		return Type{}, errors.New("type name cannot be empty")
+	}
+	return fetch[Type](ctx, c, c.baseURL+"/type/"+typeName, c.resourceTTL)
 }
@@ -0,0 +1,143 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetLocationAreasErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		check   func(error) bool
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+			check: func(err error) bool {
+				var target *NotFoundError
+				return errors.As(err, &target) && target.StatusCode == http.StatusNotFound
+			},
+		},
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			check: func(err error) bool {
+				var target *RateLimitError
+				return errors.As(err, &target)
+			},
+		},
+		{
+			name: "bad json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{not json"))
+			},
+			check: func(err error) bool {
+				var target *DecodeError
+				return errors.As(err, &target)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(c.handler)
+			defer server.Close()
+
+			client := NewClient(time.Second, time.Minute, WithRetryPolicy(RetryPolicy{}))
+			defer client.Close()
+			url := server.URL + "/location-area"
+			_, err := client.GetLocationAreas(&url)
+			if !c.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetLocationAreasTransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL + "/location-area"
+	server.Close()
+
+	client := NewClient(time.Second, time.Minute, WithRetryPolicy(RetryPolicy{}))
+	defer client.Close()
+	_, err := client.GetLocationAreas(&url)
+	var target *TransportError
+	if !errors.As(err, &target) {
+		t.Fatalf("expected TransportError, got %v", err)
+	}
+	if target.URL != url {
+		t.Errorf("expected URL %s, got %s", url, target.URL)
+	}
+}
+
+func TestGetLocationAreasContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(5*time.Second, time.Minute)
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	url := server.URL + "/location-area"
+	_, err := client.GetLocationAreasContext(ctx, &url)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(10*time.Millisecond, time.Minute, WithRetryPolicy(RetryPolicy{}))
+	defer client.Close()
+	url := server.URL + "/location-area"
+	_, err := client.GetLocationAreas(&url)
+	var target *TransportError
+	if !errors.As(err, &target) {
+		t.Errorf("expected TransportError from client timeout, got %v", err)
+	}
+}
+
+func TestFetchUsesCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"name": "pikachu", "base_experience": 112}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute, WithBaseURL(server.URL))
+	defer client.Close()
+	for range 2 {
+		pokemon, err := client.CatchPokemon("pikachu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+			t.Errorf("unexpected pokemon: %+v", pokemon)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
@@ -0,0 +1,71 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestsPerSecond and DefaultBurst keep bulk tools within
+	// PokeAPI's fair-use expectations unless WithRateLimit says otherwise.
+	DefaultRequestsPerSecond = 10
+	DefaultBurst             = 10
+)
+
+// rateLimiter is a token bucket shared by every network request of a Client.
+// Cache hits never reach it, so they do not consume the budget.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns nil, meaning unlimited, when rps is not positive.
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	burst = max(burst, 1)
+	return &rateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done. Tokens are reserved
+// up front, so concurrent callers queue in arrival order.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Hand the unused reservation back to the bucket.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
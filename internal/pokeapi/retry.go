@@ -0,0 +1,88 @@
+package pokeapi
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/httpreplay"
+)
+
+// RetryPolicy controls how the client retries GETs that fail with a transport
+// error, 429 Too Many Requests or a 5xx status.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles with every
+	// further attempt, with random jitter, up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than MaxDelay is not
+	// waited for and the error is returned instead. Zero uses the MaxDelay
+	// of DefaultRetryPolicy.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy says otherwise.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// delay returns how long to wait before retrying after the given failed
+// attempt, or false if err should not be retried.
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	retryAfter, ok := transient(err)
+	if !ok {
+		return 0, false
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= maxDelay
+	}
+
+	// Double step by step rather than shifting, which overflows for large
+	// attempts, and stop at maxDelay.
+	backoff := min(p.BaseDelay, maxDelay)
+	for i := 1; i < attempt && backoff > 0 && backoff < maxDelay; i++ {
+		if backoff > maxDelay/2 {
+			backoff = maxDelay
+		} else {
+			backoff *= 2
+		}
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+	// Equal jitter: wait at least half the backoff so retries stay spaced out.
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// transient reports whether err is a failure that may go away on its own:
+// a transport error, 429 Too Many Requests or a 5xx status. A request missing
+// from a replayed cassette is not. It also returns the wait requested by the
+// server, if any.
+func transient(err error) (time.Duration, bool) {
+	var (
+		transportErr *TransportError
+		rateLimitErr *RateLimitError
+		statusErr    *StatusError
+	)
+	switch {
+	case err == nil, errors.Is(err, httpreplay.ErrNotRecorded):
+		return 0, false
+	case errors.As(err, &transportErr):
+		return 0, true
+	case errors.As(err, &rateLimitErr):
+		return rateLimitErr.RetryAfter, true
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusInternalServerError:
+		return statusErr.RetryAfter, true
+	default:
+		return 0, false
+	}
+}
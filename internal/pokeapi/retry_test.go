@@ -0,0 +1,182 @@
+package pokeapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/httpreplay"
+)
+
+var fastRetries = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+}
+
+func TestRetryRecoversFromServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute, WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	defer client.Close()
+
+	if _, err := client.CatchPokemon("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second, time.Minute, WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+	defer client.Close()
+
+	_, err := client.CatchPokemon("pikachu")
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Fatalf("expected RetryError after 3 attempts, got %v", err)
+	}
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Errorf("expected wrapped RateLimitError, got %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestRetryNotAttempted(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+		},
+		{
+			name: "retry-after beyond max delay",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				c.handler(w, r)
+			}))
+			defer server.Close()
+
+			client := NewClient(time.Second, time.Minute, WithBaseURL(server.URL), WithRetryPolicy(fastRetries))
+			defer client.Close()
+
+			if _, err := client.CatchPokemon("pikachu"); err == nil {
+				t.Fatal("expected an error")
+			}
+			if n := requests.Load(); n != 1 {
+				t.Errorf("expected 1 request, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRetryNotAttemptedForUnrecordedRequest(t *testing.T) {
+	replayer := httpreplay.NewReplayer(httpreplay.Cassette{})
+	client := NewClient(time.Second, time.Minute, WithTransport(replayer))
+	defer client.Close()
+
+	_, err := client.CatchPokemon("pikachu")
+	if !errors.Is(err, httpreplay.ErrNotRecorded) {
+		t.Fatalf("expected ErrNotRecorded, got %v", err)
+	}
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		t.Errorf("expected no retries, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: 0},
+		{value: "3", expected: 3 * time.Second},
+		{value: "-1", expected: 0},
+		{value: "soon", expected: 0},
+		{value: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0},
+	}
+
+	for _, c := range cases {
+		if actual := parseRetryAfter(c.value); actual != c.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", c.value, actual, c.expected)
+		}
+	}
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if actual := parseRetryAfter(future); actual <= 0 || actual > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %v, expected up to a minute", future, actual)
+	}
+}
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	err := &TransportError{Err: errors.New("connection reset")}
+	for _, attempt := range []int{1, 5, 37, 64, 1000} {
+		delay, ok := DefaultRetryPolicy.delay(attempt, err)
+		if !ok {
+			t.Fatalf("attempt %d: expected a retry", attempt)
+		}
+		if delay <= 0 || delay > DefaultRetryPolicy.MaxDelay {
+			t.Errorf("attempt %d: delay %v outside (0, %v]", attempt, delay, DefaultRetryPolicy.MaxDelay)
+		}
+		if attempt >= 37 && delay < DefaultRetryPolicy.MaxDelay/2 {
+			t.Errorf("attempt %d: expected the backoff to stay at MaxDelay, got %v", attempt, delay)
+		}
+	}
+}
+
+func TestRetryDelayWithoutMaxDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond}
+	err := &TransportError{Err: errors.New("connection reset")}
+	for attempt := 1; attempt < policy.MaxAttempts; attempt++ {
+		delay, ok := policy.delay(attempt, err)
+		if !ok || delay < policy.BaseDelay/2 {
+			t.Errorf("attempt %d: expected a backoff of at least %v, got %v, %v", attempt, policy.BaseDelay/2, delay, ok)
+		}
+	}
+	if delay, _ := policy.delay(1000, err); delay > DefaultRetryPolicy.MaxDelay {
+		t.Errorf("expected the default MaxDelay to cap the backoff, got %v", delay)
+	}
+
+	rateLimited := &RateLimitError{RetryAfter: time.Second}
+	if delay, ok := policy.delay(1, rateLimited); !ok || delay != time.Second {
+		t.Errorf("expected to wait out Retry-After: 1s, got %v, %v", delay, ok)
+	}
+}
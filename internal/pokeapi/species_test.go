@@ -0,0 +1,53 @@
+package pokeapi_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokeapitest"
+)
+
+func TestGetPokemonSpecies(t *testing.T) {
+	server := pokeapitest.NewServer()
+	defer server.Close()
+	client := pokeapi.NewClient(time.Second, time.Minute, pokeapi.WithBaseURL(server.BaseURL()))
+	defer client.Close()
+
+	species, err := client.GetPokemonSpecies("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if species.CaptureRate != 190 || species.BaseHappiness != 50 || species.GrowthRate.Name != "medium" {
+		t.Errorf("unexpected species stats: %+v", species)
+	}
+	if species.IsLegendary || species.IsMythical {
+		t.Errorf("pikachu is neither legendary nor mythical")
+	}
+	if species.EvolvesFromSpecies == nil || species.EvolvesFromSpecies.Name != "pichu" {
+		t.Errorf("expected pikachu to evolve from pichu, got %+v", species.EvolvesFromSpecies)
+	}
+	if !strings.HasSuffix(species.EvolutionChain.URL, "/evolution-chain/10/") {
+		t.Errorf("unexpected evolution chain URL %s", species.EvolutionChain.URL)
+	}
+	if genus := species.Genus("en"); genus != "Mouse Pokémon" {
+		t.Errorf("unexpected genus %q", genus)
+	}
+	if text := species.FlavorText("en"); strings.ContainsAny(text, "\n\f") || !strings.HasPrefix(text, "When several of these") {
+		t.Errorf("expected flavor text with collapsed whitespace, got %q", text)
+	}
+	if text := species.FlavorText("ja"); text != "" {
+		t.Errorf("expected no Japanese flavor text, got %q", text)
+	}
+}
+
+func TestResourceID(t *testing.T) {
+	if id, err := pokeapi.ResourceID("https://pokeapi.co/api/v2/evolution-chain/10/"); err != nil || id != 10 {
+		t.Errorf("expected 10, got %d (%v)", id, err)
+	}
+	if _, err := pokeapi.ResourceID("https://pokeapi.co/api/v2/pokemon/pikachu"); err == nil {
+		t.Errorf("expected error for named resource URL")
+	}
+}
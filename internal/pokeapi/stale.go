@@ -0,0 +1,86 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokecache"
+)
+
+// StalePolicy decides when the client may serve an expired cache entry.
+// Expired entries are only available while the cache retains them, see
+// pokecache.WithStaleRetention.
+type StalePolicy int
+
+const (
+	// StaleNever always refreshes expired entries before returning them.
+	StaleNever StalePolicy = iota
+	// StaleIfError serves an expired entry when refreshing it fails with a
+	// transient error, e.g. while the network is down.
+	StaleIfError
+	// StaleWhileRevalidate serves an expired entry immediately and refreshes
+	// it in the background. A failed refresh leaves the stale entry in place.
+	StaleWhileRevalidate
+)
+
+var stalePolicyNames = map[StalePolicy]string{
+	StaleNever:           "never",
+	StaleIfError:         "if-error",
+	StaleWhileRevalidate: "while-revalidate",
+}
+
+func (p StalePolicy) String() string {
+	if name, ok := stalePolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("StalePolicy(%d)", int(p))
+}
+
+// ParseStalePolicy parses the names printed by StalePolicy.String.
+func ParseStalePolicy(name string) (StalePolicy, error) {
+	for policy, policyName := range stalePolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+	return StaleNever, fmt.Errorf("unknown stale policy %q", name)
+}
+
+// FetchInfo reports how the fetches made with a context returned by
+// WithFetchInfo were served. It is safe for concurrent fetches.
+type FetchInfo struct {
+	stale atomic.Bool
+}
+
+// Stale reports whether any response was served from an expired cache entry.
+func (i *FetchInfo) Stale() bool {
+	return i.stale.Load()
+}
+
+type fetchInfoKey struct{}
+
+// WithFetchInfo returns a context that records into info how fetches made
+// with it were served.
+func WithFetchInfo(ctx context.Context, info *FetchInfo) context.Context {
+	return context.WithValue(ctx, fetchInfoKey{}, info)
+}
+
+func markStale(ctx context.Context) {
+	if info, ok := ctx.Value(fetchInfoKey{}).(*FetchInfo); ok {
+		info.stale.Store(true)
+	}
+}
+
+// revalidate refreshes url in the background. Close cancels and waits for
+// pending refreshes.
+func (c *Client) revalidate(url string, ttl time.Duration, stale *pokecache.Entry) {
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+		c.flights.do(c.backgroundCtx, url, func(ctx context.Context) ([]byte, error) {
+			return c.download(ctx, url, ttl, stale)
+		})
+	}()
+}
@@ -0,0 +1,86 @@
+// Package pokeapitest provides a local stand-in for PokeAPI that serves
+// fixture JSON, so the client and the REPL commands can be tested offline.
+package pokeapitest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+)
+
+// apiPrefix mirrors the path prefix of the real PokeAPI.
+const apiPrefix = "/api/v2"
+
+// baseURLPlaceholder is replaced in fixtures with the server's own base URL,
+// so "next" links and resource URLs point back at the fake server.
+const baseURLPlaceholder = "{{base}}"
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Server is an httptest.Server answering a subset of the PokeAPI endpoints,
+// with ETag revalidation like the real API:
+//
+//	/location-area?offset=N  -> fixtures/location-area/page-N.json
+//	/<resource>/<name>       -> fixtures/<resource>/<name>.json
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer starts a fake PokeAPI. Callers must Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// BaseURL is the value to pass to pokeapi.WithBaseURL.
+func (s *Server) BaseURL() string {
+	return s.URL + apiPrefix
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resource, ok := strings.CutPrefix(strings.TrimSuffix(r.URL.Path, "/"), apiPrefix+"/")
+	if !ok || resource == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	name := resource + ".json"
+	if !strings.Contains(resource, "/") {
+		offset := r.URL.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		name = path.Join(resource, "page-"+offset+".json")
+	}
+
+	data, err := fs.ReadFile(fixtures, path.Join("fixtures", name))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body := bytes.ReplaceAll(data, []byte(baseURLPlaceholder), []byte(s.BaseURL()))
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,142 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiskCache stores cache entries as files in a directory so they survive
+// restarts. It is used as the second tier behind Cache's in-memory map.
+type DiskCache struct {
+	dir string
+}
+
+type diskEntry struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Val       []byte    `json:"val"`
+
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// DefaultDir returns the per-user cache directory for the Pokedex,
+// $XDG_CACHE_HOME/pokedexcli on Linux.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pokedexcli"), nil
+}
+
+// NewDiskCache creates dir if needed and returns a DiskCache backed by it.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *DiskCache) load(key string) (cacheEntry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return cacheEntry{}, false
+	}
+	return cacheEntry{
+		createdAt: entry.CreatedAt,
+		expiresAt: entry.ExpiresAt,
+		val:       entry.Val,
+		validators: Validators{
+			ETag:         entry.ETag,
+			LastModified: entry.LastModified,
+		},
+	}, true
+}
+
+func (d *DiskCache) store(key string, entry cacheEntry) error {
+	data, err := json.Marshal(diskEntry{
+		Key:       key,
+		CreatedAt: entry.createdAt,
+		ExpiresAt: entry.expiresAt,
+		Val:       entry.val,
+
+		ETag:         entry.validators.ETag,
+		LastModified: entry.validators.LastModified,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn entry.
+	tmp, err := os.CreateTemp(d.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	// The modification time records the expiry, so prune can find expired
+	// entries without reading them.
+	if err := os.Chtimes(tmp.Name(), entry.expiresAt, entry.expiresAt); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+// remove deletes the entry for key, reporting whether there was one.
+func (d *DiskCache) remove(key string) bool {
+	return os.Remove(d.path(key)) == nil
+}
+
+// prune deletes the entries that expired before cutoff, going by the
+// modification time store sets to the expiry. It is best effort: files it
+// cannot stat are skipped.
+func (d *DiskCache) prune(cutoff time.Time) {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(file)
+		}
+	}
+}
+
+// clear removes every entry file from the cache directory.
+func (d *DiskCache) clear() error {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package pokecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewCache(time.Minute, WithDisk(disk))
+	defer cache.Close()
+	validators := Validators{ETag: `"v1"`}
+	cache.AddWithValidators("https://example.com", []byte("testdata"), 0, validators)
+
+	// A fresh cache over the same directory stands in for a new session.
+	restarted := NewCache(time.Minute, WithDisk(disk))
+	defer restarted.Close()
+	val, ok := restarted.Get("https://example.com")
+	if !ok {
+		t.Fatalf("expected to find key on disk")
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected testdata, got %s", val)
+	}
+	if entry, _ := restarted.Lookup("https://example.com"); entry.Validators != validators {
+		t.Errorf("expected validators %+v, got %+v", validators, entry.Validators)
+	}
+}
+
+func TestDiskCacheExpiredEntry(t *testing.T) {
+	disk, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "https://example.com"
+	disk.store(key, cacheEntry{
+		createdAt: time.Now().Add(-time.Hour),
+		expiresAt: time.Now().Add(-time.Minute),
+		val:       []byte("stale"),
+	})
+
+	cache := NewCache(time.Minute, WithDisk(disk))
+	defer cache.Close()
+	if _, ok := cache.Get(key); ok {
+		t.Errorf("expected expired entry to be ignored")
+	}
+	if _, err := os.Stat(disk.path(key)); !os.IsNotExist(err) {
+		t.Errorf("expected expired entry to be removed from disk")
+	}
+}
+
+func TestDiskCacheIgnoresCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "https://example.com"
+	if err := os.WriteFile(disk.path(key), []byte("{garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := disk.load(key); ok {
+		t.Errorf("expected corrupt entry to be a miss")
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, ".entry-*"))
+	if len(matches) != 0 {
+		t.Errorf("unexpected temporary files: %v", matches)
+	}
+}
+
+func TestReapPrunesDiskFiles(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCache(time.Minute, WithDisk(disk), WithStaleRetention(time.Hour))
+	defer cache.Close()
+
+	cache.AddWithTTL("short", []byte("1"), time.Minute)
+	cache.AddWithTTL("long", []byte("2"), 24*time.Hour)
+	// Entries written by an earlier session are not in memory.
+	disk.store("old", cacheEntry{expiresAt: time.Now().Add(-2 * time.Hour), val: []byte("3")})
+	if err := os.WriteFile(filepath.Join(dir, "garbage.json"), []byte("{garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// short is expired but still within the retention window.
+	cache.reap(time.Now().Add(30 * time.Minute))
+	if _, ok := disk.load("short"); !ok {
+		t.Errorf("expected short to be kept for revalidation")
+	}
+	if _, ok := disk.load("old"); ok {
+		t.Errorf("expected old to be pruned from disk")
+	}
+
+	cache.reap(time.Now().Add(3 * time.Hour))
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 || files[0] != disk.path("long") {
+		t.Errorf("expected only long to remain on disk, got %v", files)
+	}
+}
@@ -1,24 +1,154 @@
 package pokecache
 
 import (
+	"container/list"
+	"sort"
 	"sync"
 	"time"
 )
 
 type Cache struct {
-	data map[string]cacheEntry
+	data map[string]*list.Element
 	mux  *sync.Mutex
+	disk *DiskCache
+
+	// ttl is how long entries added with Add stay fresh.
+	ttl time.Duration
+	// retention is how long expired entries are kept for revalidation.
+	retention time.Duration
+
+	// lru orders entries from most (front) to least (back) recently used.
+	lru        *list.List
+	size       int
+	maxBytes   int
+	maxEntries int
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+
+	// done is closed by Close to stop reapLoop, which closes stopped on exit.
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
 }
 
 type cacheEntry struct {
-	createdAt time.Time
-	val       []byte
+	createdAt  time.Time
+	expiresAt  time.Time
+	val        []byte
+	validators Validators
+}
+
+func (e cacheEntry) expired(now time.Time) bool {
+	return now.After(e.expiresAt)
+}
+
+// Validators are the HTTP response headers used to revalidate an entry with
+// a conditional request once it has expired.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Entry is a cached value as returned by Lookup.
+type Entry struct {
+	Val        []byte
+	Validators Validators
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// Expired reports whether the entry is past its TTL and only retained for
+// revalidation.
+func (e Entry) Expired() bool {
+	return time.Now().After(e.ExpiresAt)
+}
+
+// Stats is a snapshot of the cache's counters and in-memory contents.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	// Evictions counts entries dropped for exceeding the size limits or
+	// being reaped after their TTL.
+	Evictions uint64
+	Entries   int
+	Bytes     int
+}
+
+// EntryInfo describes a single in-memory entry.
+type EntryInfo struct {
+	Key  string
+	Age  time.Duration
+	Size int
+}
+
+// lruItem is the value stored in each element of Cache.lru.
+type lruItem struct {
+	key   string
+	entry cacheEntry
+}
+
+func (i *lruItem) size() int {
+	return len(i.key) + len(i.entry.val)
+}
+
+// Option configures a Cache in NewCache.
+type Option func(*Cache)
+
+// WithDisk layers a DiskCache behind the in-memory map: entries are written
+// through to disk and memory misses are looked up there.
+func WithDisk(disk *DiskCache) Option {
+	return func(c *Cache) {
+		c.disk = disk
+	}
+}
+
+// WithTTL sets how long entries added with Add stay fresh. It defaults to
+// the reap interval passed to NewCache.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithStaleRetention keeps entries for d past their expiry so that Lookup
+// can still return them, e.g. to revalidate them with a conditional request.
+// Get never returns expired entries. It defaults to zero.
+func WithStaleRetention(d time.Duration) Option {
+	return func(c *Cache) {
+		c.retention = d
+	}
 }
 
-func NewCache(interval time.Duration) Cache {
-	c := Cache{
-		data: make(map[string]cacheEntry),
+// WithMaxBytes bounds the total size of keys and values held in memory.
+// The least recently used entries are evicted first. Zero means no limit.
+func WithMaxBytes(n int) Option {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// WithMaxEntries bounds the number of entries held in memory.
+// The least recently used entries are evicted first. Zero means no limit.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+func NewCache(interval time.Duration, opts ...Option) *Cache {
+	c := &Cache{
+		data: make(map[string]*list.Element),
 		mux:  &sync.Mutex{},
+		lru:  list.New(),
+		ttl:  interval,
+
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 
 	go c.reapLoop(interval)
@@ -26,40 +156,286 @@ func NewCache(interval time.Duration) Cache {
 	return c
 }
 
+// Add stores val under key for the cache's default TTL.
 func (c *Cache) Add(key string, val []byte) {
-	c.mux.Lock()
-	defer c.mux.Unlock()
+	c.AddWithTTL(key, val, c.ttl)
+}
+
+// AddWithTTL stores val under key, overriding the default TTL for this entry.
+// A non-positive ttl uses the default.
+func (c *Cache) AddWithTTL(key string, val []byte, ttl time.Duration) {
+	c.AddWithValidators(key, val, ttl, Validators{})
+}
+
+// AddWithValidators is like AddWithTTL but also records the validators
+// needed to revalidate the entry once it expires.
+func (c *Cache) AddWithValidators(key string, val []byte, ttl time.Duration, validators Validators) {
+	now := time.Now()
+	c.store(key, cacheEntry{
+		createdAt:  now,
+		expiresAt:  now.Add(c.entryTTL(ttl)),
+		val:        val,
+		validators: validators,
+	})
+}
 
-	c.data[key] = cacheEntry{
-		createdAt: time.Now(),
-		val:       val,
+// Refresh marks an existing entry as fresh again for ttl, typically after
+// the server answered a conditional request with 304 Not Modified.
+// A non-positive ttl uses the default. It reports whether key was cached.
+func (c *Cache) Refresh(key string, ttl time.Duration) bool {
+	now := time.Now()
+	entry, exists := c.lookup(key, now)
+	if !exists {
+		return false
 	}
+	entry.createdAt = now
+	entry.expiresAt = now.Add(c.entryTTL(ttl))
+	c.store(key, entry)
+	return true
 }
 
 func (c *Cache) Get(key string) ([]byte, bool) {
+	now := time.Now()
+	entry, exists := c.lookup(key, now)
+
 	c.mux.Lock()
 	defer c.mux.Unlock()
-
-	entry, exists := c.data[key]
-	if !exists || time.Since(entry.createdAt) > 5*time.Minute {
+	if !exists || entry.expired(now) {
+		c.misses++
 		return nil, false
 	}
+	c.hits++
 	return entry.val, true
 }
 
+// Lookup is like Get but also returns expired entries that are still within
+// the stale retention window; check Entry.Expired before serving them.
+// Only fresh entries count as hits.
+func (c *Cache) Lookup(key string) (Entry, bool) {
+	now := time.Now()
+	entry, exists := c.lookup(key, now)
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if !exists || entry.expired(now) {
+		c.misses++
+	} else {
+		c.hits++
+	}
+	if !exists {
+		return Entry{}, false
+	}
+	return Entry{
+		Val:        entry.val,
+		Validators: entry.validators,
+		CreatedAt:  entry.createdAt,
+		ExpiresAt:  entry.expiresAt,
+	}, true
+}
+
+// lookup finds key in memory, then on disk, promoting disk hits to memory.
+// Entries past their retention window are dropped. c.mux must not be held:
+// it is released while the disk is read so other keys are not blocked.
+func (c *Cache) lookup(key string, now time.Time) (cacheEntry, bool) {
+	c.mux.Lock()
+	entry, exists := c.lookupMemory(key, now)
+	c.mux.Unlock()
+	if exists || c.disk == nil {
+		return entry, exists
+	}
+
+	entry, exists = c.disk.load(key)
+	if !exists {
+		return cacheEntry{}, false
+	}
+	if c.discardable(entry, now) {
+		c.disk.remove(key)
+		return cacheEntry{}, false
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	// Prefer an entry stored while the disk was being read.
+	if newer, exists := c.lookupMemory(key, now); exists {
+		return newer, true
+	}
+	c.set(key, entry)
+	return entry, true
+}
+
+// lookupMemory finds key in memory, dropping it if it is past its retention
+// window. c.mux must be held.
+func (c *Cache) lookupMemory(key string, now time.Time) (cacheEntry, bool) {
+	elem, exists := c.data[key]
+	if !exists {
+		return cacheEntry{}, false
+	}
+	item := elem.Value.(*lruItem)
+	if c.discardable(item.entry, now) {
+		c.removeElement(elem)
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return item.entry, true
+}
+
+// store saves entry in memory and writes it through to disk. c.mux must not
+// be held.
+func (c *Cache) store(key string, entry cacheEntry) {
+	c.mux.Lock()
+	c.set(key, entry)
+	c.mux.Unlock()
+
+	if c.disk != nil {
+		// The disk tier is best effort; the entry is still served from memory.
+		c.disk.store(key, entry)
+	}
+}
+
+func (c *Cache) entryTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return c.ttl
+	}
+	return ttl
+}
+
+// discardable reports whether entry is expired and past the retention window.
+func (c *Cache) discardable(entry cacheEntry, now time.Time) bool {
+	return entry.expired(now.Add(-c.retention))
+}
+
+// Remove deletes key from memory and disk, reporting whether it was cached.
+func (c *Cache) Remove(key string) bool {
+	c.mux.Lock()
+	elem, found := c.data[key]
+	if found {
+		c.removeElement(elem)
+	}
+	c.mux.Unlock()
+
+	if c.disk != nil && c.disk.remove(key) {
+		found = true
+	}
+	return found
+}
+
+// Clear deletes every entry from memory and disk. Counters are kept.
+func (c *Cache) Clear() error {
+	c.mux.Lock()
+	c.data = make(map[string]*list.Element)
+	c.lru.Init()
+	c.size = 0
+	c.mux.Unlock()
+
+	if c.disk != nil {
+		return c.disk.clear()
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the cache's counters.
+func (c *Cache) Stats() Stats {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+		Bytes:     c.size,
+	}
+}
+
+// Entries describes the in-memory entries, oldest first.
+func (c *Cache) Entries() []EntryInfo {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	now := time.Now()
+	entries := make([]EntryInfo, 0, c.lru.Len())
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		item := elem.Value.(*lruItem)
+		entries = append(entries, EntryInfo{
+			Key:  item.key,
+			Age:  now.Sub(item.entry.createdAt),
+			Size: item.size(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Age > entries[j].Age
+	})
+	return entries
+}
+
+// set stores entry in memory as the most recently used and evicts from the
+// back of the LRU list until the cache is within its limits.
+func (c *Cache) set(key string, entry cacheEntry) {
+	if elem, exists := c.data[key]; exists {
+		c.removeElement(elem)
+	}
+
+	item := &lruItem{key: key, entry: entry}
+	c.data[key] = c.lru.PushFront(item)
+	c.size += item.size()
+
+	for c.overLimit() {
+		c.removeElement(c.lru.Back())
+		c.evictions++
+	}
+}
+
+func (c *Cache) overLimit() bool {
+	if c.lru.Len() == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.size > c.maxBytes)
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	item := c.lru.Remove(elem).(*lruItem)
+	delete(c.data, item.key)
+	c.size -= item.size()
+}
+
+// Close stops the background reaper and waits for it to exit. The cache
+// remains usable afterwards, but expired entries are no longer reaped.
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	<-c.stopped
+}
+
 func (c *Cache) reapLoop(interval time.Duration) {
+	defer close(c.stopped)
+
 	ticker := time.NewTicker(interval)
-	for range ticker.C {
-		c.reap(time.Now().UTC(), interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.reap(time.Now())
+		case <-c.done:
+			return
+		}
 	}
 }
 
-func (c *Cache) reap(now time.Time, last time.Duration) {
+// reap drops entries past their retention window from memory, then deletes
+// their files from disk, which would otherwise only go when looked up again.
+func (c *Cache) reap(now time.Time) {
 	c.mux.Lock()
-	defer c.mux.Unlock()
-	for k, v := range c.data {
-		if v.createdAt.Before(now.Add(-last)) {
-			delete(c.data, k)
+	for _, elem := range c.data {
+		if c.discardable(elem.Value.(*lruItem).entry, now) {
+			c.removeElement(elem)
+			c.evictions++
 		}
 	}
+	c.mux.Unlock()
+
+	if c.disk != nil {
+		c.disk.prune(now.Add(-c.retention))
+	}
 }
@@ -2,10 +2,44 @@ package pokecache
 
 import (
 	"fmt"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
 
+// TestMain fails the package if any test leaves a reaper goroutine running.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code == 0 {
+		if n := waitForReapers(0); n > 0 {
+			fmt.Fprintf(os.Stderr, "%d reaper goroutine(s) leaked\n", n)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+func reaperGoroutines() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	return strings.Count(string(buf), "pokecache.(*Cache).reapLoop")
+}
+
+// waitForReapers waits up to a second for the number of reaper goroutines to
+// reach want and returns the last count. A reaper whose Close has returned
+// may still be on the stack running its deferred calls, and a new one may
+// not have been scheduled yet.
+func waitForReapers(want int) int {
+	n := reaperGoroutines()
+	for i := 0; i < 1000 && n != want; i++ {
+		time.Sleep(time.Millisecond)
+		n = reaperGoroutines()
+	}
+	return n
+}
+
 func TestAddGet(t *testing.T) {
 	const interval = 5 * time.Second
 	cases := []struct {
@@ -25,6 +59,7 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -43,6 +78,7 @@ func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
 	const waitTime = baseTime + 5*time.Millisecond
 	cache := NewCache(baseTime)
+	defer cache.Close()
 	cache.Add("https://example.com", []byte("testdata"))
 
 	_, ok := cache.Get("https://example.com")
@@ -59,3 +95,177 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestMaxEntriesEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(time.Minute, WithMaxEntries(2))
+	defer cache.Close()
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("expected to find a")
+	}
+	cache.Add("c", []byte("3"))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected to find %s", key)
+		}
+	}
+}
+
+func TestMaxBytesEvictsLeastRecentlyUsed(t *testing.T) {
+	// Each entry is a one byte key plus a four byte value.
+	cache := NewCache(time.Minute, WithMaxBytes(10))
+	defer cache.Close()
+	cache.Add("a", []byte("1111"))
+	cache.Add("b", []byte("2222"))
+	cache.Add("c", []byte("3333"))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+	if cache.size > 10 {
+		t.Errorf("expected size within limit, got %d", cache.size)
+	}
+
+	// Replacing a key must not double count its size.
+	cache.Add("c", []byte("33"))
+	if cache.size != 8 {
+		t.Errorf("expected size 8, got %d", cache.size)
+	}
+}
+
+func TestGetHonoursTTL(t *testing.T) {
+	cache := NewCache(time.Minute, WithTTL(5*time.Millisecond))
+	defer cache.Close()
+	cache.Add("short", []byte("testdata"))
+	cache.AddWithTTL("long", []byte("testdata"), time.Hour)
+
+	time.Sleep(10 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("expected short-lived entry to expire")
+	}
+	if _, ok := cache.Get("long"); !ok {
+		t.Errorf("expected per-entry TTL to keep long-lived entry")
+	}
+}
+
+func TestReapHonoursPerEntryTTL(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+	cache.AddWithTTL("short", []byte("testdata"), time.Millisecond)
+	cache.AddWithTTL("long", []byte("testdata"), time.Hour)
+
+	cache.reap(time.Now().Add(time.Second))
+
+	if _, ok := cache.data["short"]; ok {
+		t.Errorf("expected short-lived entry to be reaped")
+	}
+	if _, ok := cache.data["long"]; !ok {
+		t.Errorf("expected long-lived entry to survive reaping")
+	}
+}
+
+func TestCloseStopsReaper(t *testing.T) {
+	// Every earlier test closed its caches, so their reapers are exiting.
+	before := waitForReapers(0)
+	cache := NewCache(time.Millisecond)
+
+	if got := waitForReapers(before + 1); got != before+1 {
+		t.Fatalf("expected one new reaper goroutine, got %d", got-before)
+	}
+
+	cache.Close()
+	cache.Close() // closing twice is harmless
+
+	if got := waitForReapers(before); got != before {
+		t.Errorf("expected reaper goroutine to exit, %d still running", got-before)
+	}
+}
+
+func TestStats(t *testing.T) {
+	cache := NewCache(time.Minute, WithMaxEntries(1))
+	defer cache.Close()
+
+	cache.Add("a", []byte("1111"))
+	cache.Get("a")
+	cache.Get("missing")
+	cache.Add("b", []byte("22"))
+
+	want := Stats{Hits: 1, Misses: 1, Evictions: 1, Entries: 1, Bytes: 3}
+	if got := cache.Stats(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	entries := cache.Entries()
+	if len(entries) != 1 || entries[0].Key != "b" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestRemoveAndClear(t *testing.T) {
+	disk, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCache(time.Minute, WithDisk(disk))
+	defer cache.Close()
+
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+
+	if !cache.Remove("a") {
+		t.Errorf("expected a to be removed")
+	}
+	if cache.Remove("a") {
+		t.Errorf("expected second remove to report a miss")
+	}
+	if _, ok := disk.load("a"); ok {
+		t.Errorf("expected a to be removed from disk")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be cleared from memory and disk")
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("expected empty cache, got %+v", stats)
+	}
+}
+
+func TestStaleRetention(t *testing.T) {
+	cache := NewCache(time.Minute, WithStaleRetention(time.Hour))
+	defer cache.Close()
+
+	validators := Validators{ETag: `"v1"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	cache.AddWithValidators("a", []byte("1"), time.Millisecond, validators)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected Get to ignore expired entry")
+	}
+	entry, ok := cache.Lookup("a")
+	if !ok || !entry.Expired() || entry.Validators != validators {
+		t.Fatalf("expected Lookup to return expired entry with validators, got %+v", entry)
+	}
+
+	if !cache.Refresh("a", time.Hour) {
+		t.Fatalf("expected Refresh to find a")
+	}
+	if val, ok := cache.Get("a"); !ok || string(val) != "1" {
+		t.Errorf("expected refreshed entry to be fresh")
+	}
+
+	cache.reap(time.Now().Add(3 * time.Hour))
+	if _, ok := cache.Lookup("a"); ok {
+		t.Errorf("expected entry past retention to be reaped")
+	}
+}
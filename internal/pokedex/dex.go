@@ -0,0 +1,137 @@
+package pokedex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNotCaught is returned when no caught pokemon matches a lookup.
+var ErrNotCaught = errors.New("you have not caught that pokemon")
+
+// AmbiguousError is returned when a lookup matches more than one caught
+// pokemon, e.g. a species caught twice.
+type AmbiguousError struct {
+	Ref     string
+	Matches []Pokemon
+}
+
+func (e *AmbiguousError) Error() string {
+	ids := make([]string, 0, len(e.Matches))
+	for _, match := range e.Matches {
+		ids = append(ids, strconv.Itoa(match.ID))
+	}
+	return fmt.Sprintf("%d pokemon match %s; use one of the ids %s", len(e.Matches), e.Ref, strings.Join(ids, ", "))
+}
+
+// Pokedex is a trainer's caught pokemon. Every catch is a separate
+// individual with its own id, so a species can be caught more than once.
+type Pokedex struct {
+	// entries is sorted by id.
+	entries []Pokemon
+	nextID  int
+}
+
+// New returns a pokedex holding entries. Entries without an id, such as
+// those read from old save files, are given one.
+func New(entries ...Pokemon) *Pokedex {
+	d := &Pokedex{nextID: 1}
+	for _, entry := range entries {
+		d.nextID = max(d.nextID, entry.ID+1)
+	}
+	for _, entry := range entries {
+		if entry.ID == 0 {
+			entry.ID = d.nextID
+			d.nextID++
+		}
+		d.entries = append(d.entries, entry)
+	}
+	sort.Slice(d.entries, func(i, j int) bool {
+		return d.entries[i].ID < d.entries[j].ID
+	})
+	return d
+}
+
+// Len returns the number of caught pokemon.
+func (d *Pokedex) Len() int {
+	return len(d.entries)
+}
+
+// All returns every caught pokemon, ordered by id.
+func (d *Pokedex) All() []Pokemon {
+	return append([]Pokemon(nil), d.entries...)
+}
+
+// Add stores p under a new id and returns it with the id set.
+func (d *Pokedex) Add(p Pokemon) Pokemon {
+	p.ID = d.nextID
+	d.nextID++
+	d.entries = append(d.entries, p)
+	return p
+}
+
+// Get returns the pokemon with the given id.
+func (d *Pokedex) Get(id int) (Pokemon, bool) {
+	if i, ok := d.index(id); ok {
+		return d.entries[i], true
+	}
+	return Pokemon{}, false
+}
+
+// Update replaces the pokemon with p's id. It reports false if there is none.
+func (d *Pokedex) Update(p Pokemon) bool {
+	i, ok := d.index(p.ID)
+	if ok {
+		d.entries[i] = p
+	}
+	return ok
+}
+
+// Remove deletes the pokemon with the given id. It reports false if there
+// is none.
+func (d *Pokedex) Remove(id int) bool {
+	i, ok := d.index(id)
+	if ok {
+		d.entries = append(d.entries[:i], d.entries[i+1:]...)
+	}
+	return ok
+}
+
+// Lookup finds a single caught pokemon by id, nickname or name, ignoring
+// case. It returns ErrNotCaught if nothing matches and an *AmbiguousError
+// if several pokemon do.
+func (d *Pokedex) Lookup(ref string) (Pokemon, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		if p, ok := d.Get(id); ok {
+			return p, nil
+		}
+		return Pokemon{}, ErrNotCaught
+	}
+
+	// A ref matching one pokemon's nickname and another's name is ambiguous
+	// too: either could be meant.
+	var matches []Pokemon
+	for _, p := range d.entries {
+		if strings.EqualFold(p.Nickname, ref) || strings.EqualFold(p.Name, ref) {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Pokemon{}, ErrNotCaught
+	case 1:
+		return matches[0], nil
+	default:
+		return Pokemon{}, &AmbiguousError{Ref: ref, Matches: matches}
+	}
+}
+
+func (d *Pokedex) index(id int) (int, bool) {
+	i := sort.Search(len(d.entries), func(i int) bool {
+		return d.entries[i].ID >= id
+	})
+	return i, i < len(d.entries) && d.entries[i].ID == id
+}
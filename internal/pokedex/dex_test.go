@@ -0,0 +1,89 @@
+package pokedex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewAssignsMissingIDs(t *testing.T) {
+	dex := New(Pokemon{Name: "pikachu"}, Pokemon{ID: 4, Name: "tentacool"}, Pokemon{Name: "pichu"})
+
+	var ids []int
+	for _, p := range dex.All() {
+		ids = append(ids, p.ID)
+	}
+	if len(ids) != 3 || ids[0] != 4 || ids[1] != 5 || ids[2] != 6 {
+		t.Errorf("expected ids [4 5 6], got %v", ids)
+	}
+	if added := dex.Add(Pokemon{Name: "raichu"}); added.ID != 7 {
+		t.Errorf("expected next id 7, got %d", added.ID)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dex := New()
+	first := dex.Add(Pokemon{Name: "pikachu", Nickname: "Sparky"})
+	second := dex.Add(Pokemon{Name: "pikachu"})
+	tentacool := dex.Add(Pokemon{Name: "tentacool"})
+
+	cases := []struct {
+		ref  string
+		want int
+	}{
+		{ref: "3", want: tentacool.ID},
+		{ref: "tentacool", want: tentacool.ID},
+		{ref: "TENTACOOL", want: tentacool.ID},
+		{ref: "sparky", want: first.ID},
+		{ref: "2", want: second.ID},
+	}
+	for _, c := range cases {
+		got, err := dex.Lookup(c.ref)
+		if err != nil || got.ID != c.want {
+			t.Errorf("Lookup(%q): expected #%d, got #%d (%v)", c.ref, c.want, got.ID, err)
+		}
+	}
+
+	var ambiguous *AmbiguousError
+	if _, err := dex.Lookup("pikachu"); !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("expected ambiguous lookup, got %v", err)
+	} else if want := "2 pokemon match pikachu; use one of the ids 1, 2"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+	for _, ref := range []string{"raichu", "9"} {
+		if _, err := dex.Lookup(ref); !errors.Is(err, ErrNotCaught) {
+			t.Errorf("Lookup(%q): expected ErrNotCaught, got %v", ref, err)
+		}
+	}
+}
+
+func TestLookupNicknameShadowingName(t *testing.T) {
+	dex := New()
+	dex.Add(Pokemon{Name: "pikachu", Nickname: "tentacool"})
+	dex.Add(Pokemon{Name: "tentacool"})
+
+	var ambiguous *AmbiguousError
+	if _, err := dex.Lookup("tentacool"); !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("expected a nickname and a name match to be ambiguous, got %v", err)
+	}
+}
+
+func TestUpdateAndRemove(t *testing.T) {
+	dex := New()
+	pikachu := dex.Add(Pokemon{Name: "pikachu"})
+	dex.Add(Pokemon{Name: "tentacool"})
+
+	pikachu.Note = "likes ketchup"
+	if !dex.Update(pikachu) {
+		t.Fatal("expected update to find pikachu")
+	}
+	if got, _ := dex.Get(pikachu.ID); got.Note != "likes ketchup" {
+		t.Errorf("note not updated: %+v", got)
+	}
+
+	if !dex.Remove(pikachu.ID) || dex.Remove(pikachu.ID) {
+		t.Error("expected exactly one successful removal")
+	}
+	if dex.Len() != 1 || dex.Update(pikachu) {
+		t.Errorf("unexpected pokedex after removal: %v", dex.All())
+	}
+}
@@ -0,0 +1,117 @@
+// Package pokedex holds the trainer's record of a caught pokemon, kept
+// separate from the PokeAPI types so it stays small to save and compare.
+package pokedex
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+)
+
+// Wild pokemon are met at levels MinWildLevel to MaxWildLevel.
+const (
+	MinWildLevel = 2
+	MaxWildLevel = 50
+)
+
+// MaxIV is the highest individual value a stat can roll.
+const MaxIV = 31
+
+// Pokemon is a caught pokemon: the parts of its PokeAPI resource the
+// Pokedex shows, plus when, where and how it was caught.
+type Pokemon struct {
+	// ID identifies this individual within its Pokedex; see Pokedex.Add.
+	ID        int    `json:"id"`
+	SpeciesID int    `json:"species_id"`
+	Name      string `json:"name"`
+	// Species is the species name, used to look up genus and Pokedex
+	// entries; it differs from Name for alternate forms.
+	Species        string   `json:"species"`
+	Height         int      `json:"height"`
+	Weight         int      `json:"weight"`
+	BaseExperience int      `json:"base_experience"`
+	Types          []string `json:"types"`
+	Stats          []Stat   `json:"stats"`
+	Abilities      []string `json:"abilities"`
+	// Level is zero for pokemon caught before levels were recorded.
+	Level    int       `json:"level,omitempty"`
+	CaughtAt time.Time `json:"caught_at"`
+	// Location is the location area explored last before the catch, if any.
+	Location string `json:"location,omitempty"`
+	// Attempts counts the pokeballs thrown, the successful one included.
+	Attempts int    `json:"attempts"`
+	Nickname string `json:"nickname,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// DisplayName is the nickname followed by the name, or just the name.
+func (p Pokemon) DisplayName() string {
+	if p.Nickname == "" {
+		return p.Name
+	}
+	return p.Nickname + " (" + p.Name + ")"
+}
+
+// Stat is a stat such as hp or speed: the species' base value and this
+// individual's variation on it.
+type Stat struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+	// IV is the individual value, 0 to MaxIV, rolled when the pokemon is
+	// caught. Two pokemon of a species at the same level differ by it.
+	IV int `json:"iv,omitempty"`
+}
+
+// At returns the stat of an individual at the given level, using the main
+// series formula without effort values or natures.
+func (s Stat) At(level int) int {
+	value := (2*s.Value + s.IV) * level / 100
+	if s.Name == "hp" {
+		return value + level + 10
+	}
+	return value + 5
+}
+
+// FromAPI builds the Pokedex record of p. Catch details are left for the
+// caller to fill in.
+func FromAPI(p pokeapi.Pokemon) Pokemon {
+	speciesID, err := pokeapi.ResourceID(p.Species.URL)
+	if err != nil {
+		speciesID = p.ID
+	}
+
+	caught := Pokemon{
+		SpeciesID:      speciesID,
+		Name:           p.Name,
+		Species:        p.Species.Name,
+		Height:         p.Height,
+		Weight:         p.Weight,
+		BaseExperience: p.BaseExperience,
+		Types:          make([]string, 0, len(p.Types)),
+		Stats:          make([]Stat, 0, len(p.Stats)),
+		Abilities:      make([]string, 0, len(p.Abilities)),
+	}
+	for _, item := range p.Types {
+		caught.Types = append(caught.Types, item.Type.Name)
+	}
+	for _, item := range p.Stats {
+		caught.Stats = append(caught.Stats, Stat{Name: item.Stat.Name, Value: item.BaseStat})
+	}
+	for _, item := range p.Abilities {
+		caught.Abilities = append(caught.Abilities, item.Ability.Name)
+	}
+	return caught
+}
+
+// RollIVs gives every stat of p a random individual value.
+func (p *Pokemon) RollIVs() {
+	for i := range p.Stats {
+		p.Stats[i].IV = rand.IntN(MaxIV + 1)
+	}
+}
+
+// RandomLevel rolls the level of a wild pokemon.
+func RandomLevel() int {
+	return MinWildLevel + rand.IntN(MaxWildLevel-MinWildLevel+1)
+}
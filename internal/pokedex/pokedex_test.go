@@ -0,0 +1,82 @@
+package pokedex_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokeapitest"
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
+)
+
+func TestFromAPI(t *testing.T) {
+	server := pokeapitest.NewServer()
+	defer server.Close()
+	client := pokeapi.NewClient(time.Second, time.Minute, pokeapi.WithBaseURL(server.BaseURL()))
+	defer client.Close()
+
+	pokemon, err := client.CatchPokemon("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caught := pokedex.FromAPI(pokemon)
+
+	if caught.SpeciesID != 72 || caught.Name != "tentacool" || caught.Species != "tentacool" {
+		t.Errorf("unexpected identity: %+v", caught)
+	}
+	if caught.Height != 9 || caught.BaseExperience != 67 {
+		t.Errorf("unexpected measurements: %+v", caught)
+	}
+	if want := []string{"water", "poison"}; !reflect.DeepEqual(caught.Types, want) {
+		t.Errorf("expected types %v, got %v", want, caught.Types)
+	}
+	if want := []string{"clear-body", "liquid-ooze", "rain-dish"}; !reflect.DeepEqual(caught.Abilities, want) {
+		t.Errorf("expected abilities %v, got %v", want, caught.Abilities)
+	}
+	if len(caught.Stats) != 6 || caught.Stats[4] != (pokedex.Stat{Name: "special-defense", Value: 100}) {
+		t.Errorf("unexpected stats: %v", caught.Stats)
+	}
+	if caught.Level != 0 || !caught.CaughtAt.IsZero() {
+		t.Errorf("expected catch details to be left unset, got %+v", caught)
+	}
+}
+
+func TestRandomLevel(t *testing.T) {
+	for range 100 {
+		if level := pokedex.RandomLevel(); level < pokedex.MinWildLevel || level > pokedex.MaxWildLevel {
+			t.Fatalf("level %d outside [%d, %d]", level, pokedex.MinWildLevel, pokedex.MaxWildLevel)
+		}
+	}
+}
+
+func TestStatAt(t *testing.T) {
+	cases := []struct {
+		stat     pokedex.Stat
+		level    int
+		expected int
+	}{
+		// Pikachu at level 50 with perfect individual values.
+		{stat: pokedex.Stat{Name: "hp", Value: 35, IV: 31}, level: 50, expected: 110},
+		{stat: pokedex.Stat{Name: "attack", Value: 55, IV: 31}, level: 50, expected: 75},
+		{stat: pokedex.Stat{Name: "attack", Value: 55}, level: 50, expected: 60},
+		{stat: pokedex.Stat{Name: "speed", Value: 90, IV: 10}, level: 100, expected: 195},
+	}
+	for _, c := range cases {
+		if actual := c.stat.At(c.level); actual != c.expected {
+			t.Errorf("%+v at level %d: expected %d, got %d", c.stat, c.level, c.expected, actual)
+		}
+	}
+}
+
+func TestRollIVs(t *testing.T) {
+	p := pokedex.Pokemon{Stats: []pokedex.Stat{{Name: "hp", Value: 35}, {Name: "speed", Value: 90}}}
+	for range 100 {
+		p.RollIVs()
+		for _, stat := range p.Stats {
+			if stat.IV < 0 || stat.IV > pokedex.MaxIV {
+				t.Fatalf("%s: iv %d outside [0, %d]", stat.Name, stat.IV, pokedex.MaxIV)
+			}
+		}
+	}
+}
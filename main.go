@@ -1,18 +1,137 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/i-bielik/pokedexcli/internal/httpreplay"
 	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokecache"
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
 )
 
 func main() {
-	pokeClient := pokeapi.NewClient(5*time.Second, 5*time.Minute)
+	defaultCacheDir, _ := pokecache.DefaultDir()
+	defaultData, _ := defaultDataDir()
+
+	baseURL := flag.String("base-url", os.Getenv("POKEAPI_BASE_URL"), "PokeAPI base URL (defaults to $POKEAPI_BASE_URL, then "+pokeapi.DefaultBaseURL+")")
+	cacheDir := flag.String("cache-dir", defaultCacheDir, "directory for the persistent response cache; empty disables it")
+	cacheMaxBytes := flag.Int("cache-max-bytes", 64<<20, "maximum size of the in-memory cache in bytes; 0 means no limit")
+	cacheMaxEntries := flag.Int("cache-max-entries", 0, "maximum number of in-memory cache entries; 0 means no limit")
+	cacheRetention := flag.Duration("cache-retention", pokeapi.DefaultStaleRetention, "how long expired responses are kept for revalidation")
+	stale := flag.String("stale", pokeapi.StaleIfError.String(), "when to serve expired cached responses: never, if-error or while-revalidate")
+	dataDir := flag.String("data-dir", defaultData, "directory for trainer profiles and their saved Pokedex; empty disables saving")
+	profile := flag.String("profile", defaultProfile, "trainer profile to start with")
+	offline := flag.Bool("offline", false, "never touch the network; answer only from the cache")
+	record := flag.String("record", "", "record every PokeAPI response to this cassette file")
+	replay := flag.String("replay", "", "answer PokeAPI requests from this cassette file instead of the network")
+	language := flag.String("language", "", "set the profile's language for Pokedex entries, e.g. en, fr or ja")
+	rateLimit := flag.Float64("rate-limit", pokeapi.DefaultRequestsPerSecond, "maximum PokeAPI requests per second; 0 disables the limit")
+	rateBurst := flag.Int("rate-burst", pokeapi.DefaultBurst, "number of PokeAPI requests allowed in a burst")
+	flag.Parse()
+
+	stalePolicy, err := pokeapi.ParseStalePolicy(*stale)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := validateProfileName(*profile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	opts := []pokeapi.Option{
+		pokeapi.WithRateLimit(*rateLimit, *rateBurst),
+		pokeapi.WithStalePolicy(stalePolicy),
+		pokeapi.WithOffline(*offline),
+	}
+	if *baseURL != "" {
+		opts = append(opts, pokeapi.WithBaseURL(*baseURL))
+	}
+
+	switch {
+	case *record != "" && *replay != "":
+		fmt.Fprintln(os.Stderr, "-record and -replay cannot be combined")
+		os.Exit(2)
+	case *record != "":
+		var recordOpts []httpreplay.RecorderOption
+		if *baseURL != "" {
+			// Cassettes always name the public PokeAPI, whatever was recorded.
+			recordOpts = append(recordOpts, httpreplay.WithRecordedBaseURL(*baseURL, pokeapi.DefaultBaseURL))
+		}
+		opts = append(opts, pokeapi.WithTransport(httpreplay.NewRecorder(*record, nil, recordOpts...)))
+	case *replay != "":
+		replayer, err := httpreplay.LoadReplayer(*replay)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Loading cassette:", err)
+			os.Exit(1)
+		}
+		opts = append(opts, pokeapi.WithTransport(replayer))
+	}
+
+	cacheOpts := []pokecache.Option{
+		pokecache.WithMaxBytes(*cacheMaxBytes),
+		pokecache.WithMaxEntries(*cacheMaxEntries),
+		pokecache.WithStaleRetention(*cacheRetention),
+	}
+	if *cacheDir != "" {
+		disk, err := pokecache.NewDiskCache(*cacheDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Disk cache disabled:", err)
+		} else {
+			cacheOpts = append(cacheOpts, pokecache.WithDisk(disk))
+		}
+	}
+	opts = append(opts, pokeapi.WithCacheOptions(cacheOpts...))
+
+	pokeClient := pokeapi.NewClient(5*time.Second, 5*time.Minute, opts...)
 	cfg := &config{
-		pokedex:       map[string]pokeapi.Pokemon{},
+		pokedex:       pokedex.New(),
 		pokeapiClient: pokeClient,
+		language:      defaultLanguage,
+		autosave:      true,
+		attempts:      map[string]int{},
+		catchDelay:    time.Second,
+		profile:       *profile,
+	}
+	if *dataDir != "" {
+		cfg.profilesDir = filepath.Join(*dataDir, "profiles")
+		if err := migrateSaveFile(*dataDir, cfg.profilesDir); err != nil {
+			fmt.Fprintln(os.Stderr, "Moving pokedex to the default profile:", err)
+		}
+		if err := cfg.loadProfile(*profile); err != nil {
+			// Carrying on would overwrite the pokedex we failed to read.
+			fmt.Fprintln(os.Stderr, "Loading profile:", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(cfg.profileDir(*profile), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, "Creating profile:", err)
+		}
+	}
+	if *language != "" {
+		cfg.language = *language
 	}
 
 	startRepl(cfg)
 
 }
+
+// migrateSaveFile moves a pokedex saved before trainer profiles existed into
+// the default profile.
+func migrateSaveFile(dataDir, profilesDir string) error {
+	oldPath := filepath.Join(dataDir, "pokedex.json")
+	newPath := filepath.Join(profilesDir, defaultProfile, "pokedex.json")
+	if _, err := os.Stat(oldPath); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
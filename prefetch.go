@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// prefetchWorkers bounds how many resources are downloaded at once. The
+// client's rate limiter still applies on top of it.
+const prefetchWorkers = 8
+
+func commandPrefetch(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide a region name or all")
+	}
+
+	areaNames, locationFailures, err := prefetchAreaNames(ctx, cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu       sync.Mutex
+		seen     = map[string]bool{}
+		pokemons []string
+	)
+	areaFailures := runPrefetch(ctx, "location areas", areaNames, func(ctx context.Context, name string) error {
+		area, err := cfg.pokeapiClient.GetLocationAreaContext(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, encounter := range area.PokemonEncounters {
+			if !seen[encounter.Pokemon.Name] {
+				seen[encounter.Pokemon.Name] = true
+				pokemons = append(pokemons, encounter.Pokemon.Name)
+			}
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	sort.Strings(pokemons)
+	pokemonFailures := runPrefetch(ctx, "pokemon", pokemons, func(ctx context.Context, name string) error {
+		_, err := cfg.pokeapiClient.CatchPokemonContext(ctx, name)
+		return err
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	fmt.Printf("Prefetched %d location areas and %d pokemon\n",
+		len(areaNames)-len(areaFailures), len(pokemons)-len(pokemonFailures))
+	failures := append(locationFailures, areaFailures...)
+	failures = append(failures, pokemonFailures...)
+	if len(failures) > 0 {
+		fmt.Printf("%d resources could not be fetched:\n", len(failures))
+		for _, err := range failures {
+			fmt.Println("  -", err)
+		}
+	}
+	return nil
+}
+
+// prefetchAreaNames lists the location areas of a region, or of every
+// region when target is "all". It also returns the errors of the region's
+// locations that could not be fetched; their areas are left out.
+func prefetchAreaNames(ctx context.Context, cfg *config, target string) ([]string, []error, error) {
+	var names []string
+
+	if target == "all" {
+		var pageURL *string
+		for {
+			page, err := cfg.pokeapiClient.GetLocationAreasContext(ctx, pageURL)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, area := range page.Results {
+				names = append(names, area.Name)
+			}
+			fmt.Printf("\rListing location areas: %d", len(names))
+			if page.Next == nil {
+				fmt.Println()
+				return names, nil, nil
+			}
+			pageURL = page.Next
+		}
+	}
+
+	region, err := cfg.pokeapiClient.GetRegionContext(ctx, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locationNames := make([]string, 0, len(region.Locations))
+	for _, location := range region.Locations {
+		locationNames = append(locationNames, location.Name)
+	}
+
+	var mu sync.Mutex
+	failures := runPrefetch(ctx, "locations", locationNames, func(ctx context.Context, name string) error {
+		location, err := cfg.pokeapiClient.GetLocationContext(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, area := range location.Areas {
+			names = append(names, area.Name)
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	sort.Strings(names)
+	return names, failures, nil
+}
+
+// runPrefetch calls fetch for every name on a bounded pool of workers,
+// printing progress as it goes, and returns the errors of failed fetches.
+func runPrefetch(ctx context.Context, label string, names []string, fetch func(context.Context, string) error) []error {
+	jobs := make(chan string)
+	results := make(chan error)
+
+	var wg sync.WaitGroup
+	for range min(prefetchWorkers, len(names)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				results <- fetch(ctx, name)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, name := range names {
+			select {
+			case jobs <- name:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		done     int
+		failures []error
+	)
+	for err := range results {
+		done++
+		if err != nil && ctx.Err() == nil {
+			failures = append(failures, err)
+		}
+		fmt.Printf("\rFetching %s: %d/%d", label, done, len(names))
+	}
+	if len(names) > 0 {
+		fmt.Println()
+	}
+	return failures
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
+)
+
+// defaultProfile is the trainer profile used when none is chosen.
+const defaultProfile = "default"
+
+// profileNamePattern restricts profile names to safe directory names.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// profileState is the part of a config that is kept per trainer profile,
+// besides the pokedex itself: pagination state and settings.
+type profileState struct {
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Language string  `json:"language"`
+	Autosave bool    `json:"autosave"`
+}
+
+// validateProfileName rejects names that are not safe directory names or
+// that the REPL could not type, since it lowercases input.
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, - and _", name)
+	}
+	return nil
+}
+
+func defaultProfileState() profileState {
+	return profileState{Language: defaultLanguage, Autosave: true}
+}
+
+// profileDir returns the directory holding a profile's pokedex and state.
+func (cfg *config) profileDir(name string) string {
+	return filepath.Join(cfg.profilesDir, name)
+}
+
+// listProfiles returns the names of the saved profiles, sorted.
+func (cfg *config) listProfiles() ([]string, error) {
+	entries, err := os.ReadDir(cfg.profilesDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && profileNamePattern.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// loadProfile makes name the active profile, reading its pokedex and state.
+// A profile that was never saved starts out empty with default settings. On
+// error the active profile is left unchanged.
+func (cfg *config) loadProfile(name string) error {
+	dir := cfg.profileDir(name)
+
+	state := defaultProfileState()
+	data, err := os.ReadFile(filepath.Join(dir, "profile.json"))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("reading profile %s: %w", name, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	savePath := filepath.Join(dir, "pokedex.json")
+	dex, err := loadPokedex(savePath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		dex = pokedex.New()
+	case err != nil:
+		return err
+	}
+
+	cfg.profile = name
+	cfg.savePath = savePath
+	cfg.pokedex = dex
+	cfg.Next = state.Next
+	cfg.Previous = state.Previous
+	cfg.language = state.Language
+	cfg.autosave = state.Autosave
+	cfg.location = ""
+	cfg.attempts = map[string]int{}
+	return nil
+}
+
+// saveProfile writes the active profile's state, and its pokedex too when
+// withPokedex is set. It does nothing when profiles are not persisted.
+func (cfg *config) saveProfile(withPokedex bool) error {
+	if cfg.profilesDir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(profileState{
+		Next:     cfg.Next,
+		Previous: cfg.Previous,
+		Language: cfg.language,
+		Autosave: cfg.autosave,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(cfg.profileDir(cfg.profile), "profile.json"), data); err != nil {
+		return err
+	}
+
+	if withPokedex {
+		return savePokedex(cfg.savePath, cfg.pokedex)
+	}
+	return nil
+}
+
+func commandProfile(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		fmt.Println("Active profile:", cfg.profile)
+		return nil
+	}
+	if cfg.profilesDir == "" {
+		return errors.New("profiles are disabled because there is no data directory")
+	}
+
+	if args[0] == "list" {
+		names, err := cfg.listProfiles()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if name == cfg.profile {
+				fmt.Println("  *", name)
+			} else {
+				fmt.Println("   ", name)
+			}
+		}
+		return nil
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("please provide a profile name to %s", args[0])
+	}
+	name := args[1]
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	_, statErr := os.Stat(cfg.profileDir(name))
+	exists := statErr == nil
+
+	switch args[0] {
+	case "new":
+		if exists {
+			return fmt.Errorf("profile %s already exists", name)
+		}
+		if err := os.MkdirAll(cfg.profileDir(name), 0o755); err != nil {
+			return err
+		}
+		fmt.Printf("Created profile %s\n", name)
+	case "switch":
+		if !exists {
+			return fmt.Errorf("no profile named %s; create it with profile new %s", name, name)
+		}
+		if name == cfg.profile {
+			fmt.Println("Already using profile", name)
+			return nil
+		}
+		// Loading the other profile replaces cfg.pokedex, so save it even
+		// with autosave off; unsaved catches would be lost otherwise.
+		if err := cfg.saveProfile(true); err != nil {
+			return fmt.Errorf("saving profile %s: %w", cfg.profile, err)
+		}
+		if err := cfg.loadProfile(name); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to profile %s (%d pokemon caught)\n", name, cfg.pokedex.Len())
+	case "delete":
+		if !exists {
+			return fmt.Errorf("no profile named %s", name)
+		}
+		if name == cfg.profile {
+			return errors.New("cannot delete the active profile; switch to another one first")
+		}
+		if err := os.RemoveAll(cfg.profileDir(name)); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted profile %s\n", name)
+	default:
+		return fmt.Errorf("unknown profile subcommand: %s", args[0])
+	}
+	return nil
+}
+
+func commandSettings(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) > 0 {
+		if len(args) < 2 {
+			return fmt.Errorf("please provide a value for %s", args[0])
+		}
+		switch args[0] {
+		case "language":
+			cfg.language = args[1]
+		case "autosave":
+			switch args[1] {
+			case "on":
+				cfg.autosave = true
+			case "off":
+				cfg.autosave = false
+			default:
+				return fmt.Errorf("unknown autosave mode: %s (use on or off)", args[1])
+			}
+		default:
+			return fmt.Errorf("unknown setting: %s", args[0])
+		}
+		if err := cfg.saveProfile(cfg.autosave); err != nil {
+			return err
+		}
+	}
+
+	autosave := "off"
+	if cfg.autosave {
+		autosave = "on"
+	}
+	fmt.Printf("Profile: %s\n", cfg.profile)
+	fmt.Printf("Language: %s\n", cfg.language)
+	fmt.Printf("Autosave: %s\n", autosave)
+	return nil
+}
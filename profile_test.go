@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
+)
+
+// newProfileConfig returns a test config whose profiles live in a temporary
+// directory, with the default profile active.
+func newProfileConfig(t *testing.T) *config {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.profilesDir = t.TempDir()
+	if err := cfg.loadProfile(defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(cfg.profileDir(defaultProfile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestCommandProfile(t *testing.T) {
+	cfg := newProfileConfig(t)
+	ctx := context.Background()
+
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+	if _, err := captureOutput(t, func() error { return commandMap(ctx, cfg) }); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"new", "misty"}, want: "Created profile misty"},
+		{args: []string{"list"}, want: "  * default\n    misty\n"},
+		{args: []string{"switch", "misty"}, want: "Switched to profile misty (0 pokemon caught)"},
+		{args: nil, want: "Active profile: misty"},
+		{args: []string{"switch", "default"}, want: "Switched to profile default (1 pokemon caught)"},
+		{args: []string{"delete", "misty"}, want: "Deleted profile misty"},
+	}
+	for _, step := range steps {
+		out, err := captureOutput(t, func() error { return commandProfile(ctx, cfg, step.args...) })
+		if err != nil {
+			t.Fatalf("profile %v: %v", step.args, err)
+		}
+		if !strings.Contains(out, step.want) {
+			t.Errorf("profile %v: expected %q in output %q", step.args, step.want, out)
+		}
+	}
+
+	if cfg.Next == nil {
+		t.Error("default profile lost its pagination state")
+	}
+	if err := commandProfile(ctx, cfg, "delete", defaultProfile); err == nil {
+		t.Error("expected error deleting the active profile")
+	}
+	if _, err := os.Stat(cfg.profileDir("misty")); !os.IsNotExist(err) {
+		t.Errorf("expected deleted profile directory to be gone, got %v", err)
+	}
+}
+
+func TestCommandProfileSwitchWithAutosaveOff(t *testing.T) {
+	cfg := newProfileConfig(t)
+	ctx := context.Background()
+	cfg.autosave = false
+
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caught := cfg.pokedex.Add(pokedex.FromAPI(pokemon))
+	if _, err := captureOutput(t, func() error { return commandNickname(ctx, cfg, "pikachu", "Sparky") }); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"new", "misty"}, {"switch", "misty"}} {
+		if _, err := captureOutput(t, func() error { return commandProfile(ctx, cfg, args...) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := captureOutput(t, func() error { return commandProfile(ctx, cfg, "switch", defaultProfile) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "(1 pokemon caught)") {
+		t.Errorf("pokedex lost on switch: %q", out)
+	}
+	if got, ok := cfg.pokedex.Get(caught.ID); !ok || got.Nickname != "Sparky" {
+		t.Errorf("expected Sparky to survive the switch, got %+v", got)
+	}
+	if cfg.autosave {
+		t.Error("switching should not turn autosave on")
+	}
+}
+
+func TestCommandProfileErrors(t *testing.T) {
+	cfg := newProfileConfig(t)
+	ctx := context.Background()
+
+	cases := [][]string{
+		{"switch", "brock"},
+		{"delete", "brock"},
+		{"new", defaultProfile},
+		{"new", "../escape"},
+		{"new"},
+		{"rename", "brock"},
+	}
+	for _, args := range cases {
+		if err := commandProfile(ctx, cfg, args...); err == nil {
+			t.Errorf("profile %v: expected error", args)
+		}
+	}
+
+	cfg.profilesDir = ""
+	if err := commandProfile(ctx, cfg, "list"); err == nil {
+		t.Error("expected error listing profiles without a data directory")
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"default", "misty", "team-rocket_2"} {
+		if err := validateProfileName(name); err != nil {
+			t.Errorf("%q: unexpected error %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Misty", "../../x", "a/b", "-x", "."} {
+		if err := validateProfileName(name); err == nil {
+			t.Errorf("%q: expected error", name)
+		}
+	}
+}
+
+func TestCommandSettings(t *testing.T) {
+	cfg := newProfileConfig(t)
+	ctx := context.Background()
+
+	if _, err := captureOutput(t, func() error { return commandSettings(ctx, cfg, "autosave", "off") }); err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureOutput(t, func() error { return commandSettings(ctx, cfg, "language", "fr") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Profile: default\nLanguage: fr\nAutosave: off\n"; out != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+	if err := commandSettings(ctx, cfg, "autosave", "maybe"); err == nil {
+		t.Error("expected error for unknown autosave mode")
+	}
+
+	// Settings are saved with the profile and survive a reload.
+	cfg.language = defaultLanguage
+	if err := cfg.loadProfile(defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.language != "fr" || cfg.autosave {
+		t.Errorf("settings not persisted: language %q, autosave %v", cfg.language, cfg.autosave)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.profileDir(defaultProfile), "pokedex.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no pokedex to be saved with autosave off, got %v", err)
+	}
+}
@@ -2,20 +2,48 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
 )
 
 type config struct {
 	pokeapiClient pokeapi.Client
 	Next          *string `json:"next"`
 	Previous      *string `json:"previous"`
-	pokedex       map[string]pokeapi.Pokemon
+	pokedex       *pokedex.Pokedex
+	// language is the PokeAPI language code for Pokedex entries, e.g. "en".
+	language string
+	// savePath is the file the pokedex is autosaved to; empty disables autosave.
+	savePath string
+	// autosave saves the pokedex after every catch and on exit.
+	autosave bool
+	// profile is the active trainer profile, stored under profilesDir.
+	// An empty profilesDir keeps profiles in memory only.
+	profile     string
+	profilesDir string
+	// location is the location area explored last, recorded with each catch.
+	location string
+	// attempts counts the pokeballs thrown at each pokemon not yet caught.
+	attempts map[string]int
+	// catchDelay is how long a thrown pokeball takes to land.
+	catchDelay time.Duration
+	// catchRoll decides whether a pokeball catches p; nil leaves it to
+	// chance with pokeapi.Pokemon.AttemptCatch.
+	catchRoll func(p pokeapi.Pokemon) bool
 }
 
+// defaultLanguage is used when no language is configured or PokeAPI has no
+// text in the configured one.
+const defaultLanguage = "en"
+
 func cleanInput(text string) []string {
 	// Convert to lowercase and split into words
 	return strings.Fields(strings.ToLower(text))
@@ -24,11 +52,14 @@ func cleanInput(text string) []string {
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(*config, ...string) error
+	callback    func(context.Context, *config, ...string) error
+	// rawArgs passes arguments without lowercasing them, for file names and
+	// free text.
+	rawArgs bool
 }
 
-func startRepl(cfg *config) {
-	commands := map[string]cliCommand{
+func getCommands() map[string]cliCommand {
+	return map[string]cliCommand{
 		"exit": {
 			name:        "exit",
 			description: "Exit the Pokedex",
@@ -60,17 +91,94 @@ func startRepl(cfg *config) {
 			callback:    commandCatch,
 		},
 		"inspect": {
-			name:        "inspect <pokemon_name>",
+			name:        "inspect <pokemon | nickname | id>",
 			description: "Show basic information about a Pokemon",
 			callback:    commandInspect,
 		},
 		"pokedex": {
 			name:        "pokedex",
-			description: "Show caught Pokemons",
+			description: "Show caught Pokemons, grouped by species",
 			callback:    commandPokedex,
 		},
+		"cache": {
+			name:        "cache [clear | evict <url>]",
+			description: "Show cache statistics, or clear or evict cached responses",
+			callback:    commandCache,
+		},
+		"offline": {
+			name:        "offline [on | off]",
+			description: "Show or switch offline mode, which answers only from the cache",
+			callback:    commandOffline,
+		},
+		"prefetch": {
+			name:        "prefetch <region_name | all>",
+			description: "Download location areas and their Pokemon into the cache",
+			callback:    commandPrefetch,
+		},
+		"evolutions": {
+			name:        "evolutions <pokemon_name>",
+			description: "Show the evolution tree of a Pokemon",
+			callback:    commandEvolutions,
+		},
+		"move": {
+			name:        "move <move_name>",
+			description: "Show a move's power, accuracy, PP and effect",
+			callback:    commandMove,
+		},
+		"ability": {
+			name:        "ability <ability_name>",
+			description: "Show what an ability does and which Pokemon have it",
+			callback:    commandAbility,
+		},
+		"type": {
+			name:        "type <type_name>",
+			description: "Show a type's damage relations",
+			callback:    commandType,
+		},
+		"save": {
+			name:        "save [file]",
+			description: "Save the Pokedex to the save file, or to the given file",
+			callback:    commandSave,
+			rawArgs:     true,
+		},
+		"load": {
+			name:        "load <file>",
+			description: "Replace the Pokedex with one saved to the given file",
+			callback:    commandLoad,
+			rawArgs:     true,
+		},
+		"release": {
+			name:        "release <pokemon | nickname | id>",
+			description: "Release a caught Pokemon",
+			callback:    commandRelease,
+		},
+		"nickname": {
+			name:        "nickname <pokemon | nickname | id> [nickname]",
+			description: "Give a caught Pokemon a nickname, or clear it",
+			callback:    commandNickname,
+			rawArgs:     true,
+		},
+		"note": {
+			name:        "note <pokemon | nickname | id> [text]",
+			description: "Write a note about a caught Pokemon, or clear it",
+			callback:    commandNote,
+			rawArgs:     true,
+		},
+		"profile": {
+			name:        "profile [list | new <name> | switch <name> | delete <name>]",
+			description: "Show, create, switch or delete trainer profiles",
+			callback:    commandProfile,
+		},
+		"settings": {
+			name:        "settings [language <code> | autosave <on | off>]",
+			description: "Show or change the active profile's settings",
+			callback:    commandSettings,
+		},
 	}
+}
 
+func startRepl(cfg *config) {
+	commands := getCommands()
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
@@ -84,7 +192,17 @@ func startRepl(cfg *config) {
 		commandName := words[0]
 
 		if command, ok := commands[commandName]; ok {
-			command.callback(cfg, words[1:]...)
+			args := words[1:]
+			if command.rawArgs {
+				args = strings.Fields(scanner.Text())[1:]
+			}
+			if err := runCommand(cfg, command, args...); err != nil {
+				if errors.Is(err, context.Canceled) {
+					fmt.Println("Command interrupted")
+				} else {
+					fmt.Println("Error:", err)
+				}
+			}
 		} else {
 			fmt.Println("Unknown command:", commandName)
 		}
@@ -96,3 +214,17 @@ func startRepl(cfg *config) {
 	}
 
 }
+
+// runCommand runs a single command with a context that is cancelled on Ctrl-C,
+// so an interrupt aborts the command instead of terminating the process.
+func runCommand(cfg *config, command cliCommand, args ...string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var info pokeapi.FetchInfo
+	err := command.callback(pokeapi.WithFetchInfo(ctx, &info), cfg, args...)
+	if info.Stale() {
+		fmt.Println("(some data came from an expired cache entry and may be out of date)")
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokeapi"
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
+)
+
+// saveVersion is the format version written to save files. Bump it whenever
+// the format changes and teach loadPokedex to read the older versions.
+//
+// Version 1 stored the full PokeAPI resource of each pokemon; version 2
+// stores pokedex.Pokemon records; version 3 gives each of them an id, so a
+// species may appear more than once; version 4 adds individual values to
+// their stats.
+const saveVersion = 4
+
+type saveFile struct {
+	Version int             `json:"version"`
+	SavedAt time.Time       `json:"saved_at"`
+	Pokedex json.RawMessage `json:"pokedex"`
+}
+
+// savedPokemonV1 is a pokedex entry in a version 1 save file.
+type savedPokemonV1 struct {
+	Pokemon  pokeapi.Pokemon `json:"pokemon"`
+	CaughtAt time.Time       `json:"caught_at"`
+	Location string          `json:"location,omitempty"`
+	Attempts int             `json:"attempts"`
+}
+
+// defaultDataDir returns the per-user data directory for the Pokedex,
+// $XDG_DATA_HOME/pokedexcli, falling back to ~/.local/share.
+func defaultDataDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "pokedexcli"), nil
+}
+
+// savePokedex writes dex to path, creating its directory if needed.
+func savePokedex(path string, dex *pokedex.Pokedex) error {
+	list, err := json.Marshal(dex.All())
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(saveFile{
+		Version: saveVersion,
+		SavedAt: time.Now(),
+		Pokedex: list,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to path, creating its directory if needed.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a torn file.
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadPokedex reads a pokedex written by savePokedex, by this or an older
+// version. A missing file is reported with an error matching fs.ErrNotExist.
+func loadPokedex(path string) (*pokedex.Pokedex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var save saveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	switch {
+	case save.Version == 0:
+		return nil, fmt.Errorf("%s is not a pokedex save file", path)
+	case save.Version > saveVersion:
+		return nil, fmt.Errorf("%s was saved by a newer version (format %d, this version reads up to %d)", path, save.Version, saveVersion)
+	}
+
+	var entries []pokedex.Pokemon
+	if save.Version == 1 {
+		var old []savedPokemonV1
+		if err := json.Unmarshal(save.Pokedex, &old); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		for _, entry := range old {
+			migrated := pokedex.FromAPI(entry.Pokemon)
+			migrated.CaughtAt = entry.CaughtAt
+			migrated.Location = entry.Location
+			migrated.Attempts = entry.Attempts
+			entries = append(entries, migrated)
+		}
+	} else if err := json.Unmarshal(save.Pokedex, &entries); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	// Entries from before version 3 have no ids; New assigns them.
+	return pokedex.New(entries...), nil
+}
+
+// autosave writes the pokedex to the configured save file, if any and if
+// autosave is on. Failures are reported but don't fail the command that
+// triggered the save.
+func autosave(cfg *config) {
+	if cfg.savePath == "" || !cfg.autosave {
+		return
+	}
+	if err := savePokedex(cfg.savePath, cfg.pokedex); err != nil {
+		fmt.Println("Autosave failed:", err)
+	}
+}
+
+func commandSave(ctx context.Context, cfg *config, args ...string) error {
+	path := cfg.savePath
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if path == "" {
+		return errors.New("no save file configured; please provide a file name")
+	}
+
+	if err := savePokedex(path, cfg.pokedex); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %d pokemon to %s\n", cfg.pokedex.Len(), path)
+	return nil
+}
+
+func commandLoad(ctx context.Context, cfg *config, args ...string) error {
+	if len(args) == 0 {
+		return errors.New("please provide a file to load")
+	}
+
+	dex, err := loadPokedex(args[0])
+	if err != nil {
+		return err
+	}
+	cfg.pokedex = dex
+	fmt.Printf("Loaded %d pokemon from %s\n", dex.Len(), args[0])
+	return nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/i-bielik/pokedexcli/internal/pokedex"
+)
+
+func TestSaveAndLoadPokedex(t *testing.T) {
+	cfg := newTestConfig(t)
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caughtAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pikachu := pokedex.FromAPI(pokemon)
+	pikachu.Level = 7
+	pikachu.CaughtAt = caughtAt
+	pikachu.Location = "eterna-forest-area"
+	pikachu.Attempts = 2
+
+	path := filepath.Join(t.TempDir(), "nested", "pokedex.json")
+	if err := savePokedex(path, pokedex.New(pikachu, pikachu)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadPokedex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Len() != 2 {
+		t.Fatalf("expected both pikachu to be loaded, got %v", loaded.All())
+	}
+	got, ok := loaded.Get(2)
+	if !ok {
+		t.Fatalf("expected pikachu #2, got %v", loaded.All())
+	}
+	if !got.CaughtAt.Equal(caughtAt) || got.Location != "eterna-forest-area" || got.Attempts != 2 || got.Level != 7 {
+		t.Errorf("catch details not preserved: %+v", got)
+	}
+	if got.BaseExperience != 112 || len(got.Stats) != len(pokemon.Stats) || got.SpeciesID != 25 {
+		t.Errorf("pokemon not preserved: %+v", got)
+	}
+}
+
+func TestLoadPokedexMigratesV1(t *testing.T) {
+	cfg := newTestConfig(t)
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Version 1 saved the whole PokeAPI resource with the catch details.
+	caughtAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data, err := json.Marshal(map[string]any{
+		"version":  1,
+		"saved_at": caughtAt,
+		"pokedex": []savedPokemonV1{
+			{Pokemon: pokemon, CaughtAt: caughtAt, Location: "pastoria-city-area", Attempts: 4},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pokedex.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadPokedex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.Get(1)
+	if !ok {
+		t.Fatalf("expected the migrated pokemon to get id 1, got %v", loaded.All())
+	}
+	want := pokedex.FromAPI(pokemon)
+	want.ID = 1
+	want.CaughtAt = caughtAt
+	want.Location = "pastoria-city-area"
+	want.Attempts = 4
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	// Saving again writes the current version.
+	if err := savePokedex(path, loaded); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), `"version": 4`) {
+		t.Errorf("expected version 4 save file, got %s", saved)
+	}
+}
+
+func TestLoadPokedexErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := loadPokedex(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "corrupt", content: "{not json", want: "reading"},
+		{name: "unversioned", content: `{"pokedex": []}`, want: "not a pokedex save file"},
+		{name: "newer", content: `{"version": 99, "pokedex": []}`, want: "newer version"},
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name+".json")
+		if err := os.WriteFile(path, []byte(c.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadPokedex(path); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected error containing %q, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestCommandSaveAndLoad(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	if err := commandSave(ctx, cfg); err == nil {
+		t.Error("expected error saving without a save file")
+	}
+
+	cfg.savePath = filepath.Join(t.TempDir(), "pokedex.json")
+	pokemon, err := cfg.pokeapiClient.CatchPokemon("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tentacool := pokedex.FromAPI(pokemon)
+	tentacool.CaughtAt = time.Now()
+	tentacool.Attempts = 1
+	cfg.pokedex.Add(tentacool)
+
+	out, err := captureOutput(t, func() error { return commandSave(ctx, cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Saved 1 pokemon to "+cfg.savePath) {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	cfg.pokedex = pokedex.New()
+	out, err = captureOutput(t, func() error { return commandLoad(ctx, cfg, cfg.savePath) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.pokedex.Lookup("tentacool"); err != nil || !strings.Contains(out, "Loaded 1 pokemon") {
+		t.Errorf("pokedex not loaded: %v, output %q", cfg.pokedex.All(), out)
+	}
+}
+
+func TestCatchAutosaves(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.savePath = filepath.Join(t.TempDir(), "pokedex.json")
+	cfg.autosave = true
+	cfg.catchRoll = catchRolls(false, true)
+	ctx := context.Background()
+
+	if _, err := captureOutput(t, func() error { return commandExplore(ctx, cfg, "pastoria-city-area") }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first pokeball misses, so nothing is saved until the second.
+	if _, err := captureOutput(t, func() error { return commandCatch(ctx, cfg, "tentacool") }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cfg.savePath); !os.IsNotExist(err) {
+		t.Fatalf("expected no save after an escape, got %v", err)
+	}
+	if _, err := captureOutput(t, func() error { return commandCatch(ctx, cfg, "tentacool") }); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := loadPokedex(cfg.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := saved.Lookup("tentacool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Location != "pastoria-city-area" || got.Attempts != 2 || got.CaughtAt.IsZero() {
+		t.Errorf("unexpected saved entry: location %q, attempts %d, caught at %v", got.Location, got.Attempts, got.CaughtAt)
+	}
+	if got.Level < pokedex.MinWildLevel || got.Level > pokedex.MaxWildLevel {
+		t.Errorf("level %d outside the wild range", got.Level)
+	}
+	if want := cfg.pokedex.All()[0]; got.ID != want.ID || got.Attempts != want.Attempts || !got.CaughtAt.Equal(want.CaughtAt) {
+		t.Errorf("saved %+v, pokedex has %+v", got, want)
+	}
+}